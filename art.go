@@ -0,0 +1,782 @@
+package radix
+
+// maxPrefixLen bounds how many bytes of a compressed path are stored
+// inline on an inner node. Longer shared prefixes are still
+// compressed logically (prefixLen may exceed maxPrefixLen), but the
+// extra bytes are not stored; descent falls back to comparing against
+// a representative leaf's full key instead ("lazy expansion").
+const maxPrefixLen = 10
+
+// artKind identifies which of the four adaptive node layouts (or the
+// leaf layout) an *artNode represents.
+type artKind uint8
+
+const (
+	artNode4 artKind = iota
+	artNode16
+	artNode48
+	artNode256
+	artLeafKind
+)
+
+// artLeaf holds a full key and its value. Leaves are always stored at
+// the far end of a path; an artNode of kind artLeafKind wraps exactly
+// one.
+type artLeaf[T any] struct {
+	key string
+	val T
+}
+
+// artNode is the adaptive radix tree's node representation. A single
+// struct serves all four inner layouts plus the leaf layout; kind
+// selects which fields are meaningful. Inner nodes grow (4->16->48->
+// 256) when a child is added to a full node, and shrink back one size
+// class when the child count drops to 37 (256->48), 12 (48->16) or 3
+// (16->4); they may additionally carry a leaf of their own for a key that
+// terminates exactly at this node's depth (mirrors node[T].leaf in
+// the plain radix tree, since ART here does not assume keys are
+// prefix-free).
+type artNode[T any] struct {
+	kind artKind
+
+	// prefixLen is the logical length of the compressed path; only
+	// min(prefixLen, maxPrefixLen) bytes of it are stored in prefix.
+	prefixLen int
+	prefix    [maxPrefixLen]byte
+
+	leaf *artLeaf[T] // set on artLeafKind nodes, and optionally on inner nodes
+
+	numChildren int
+	keys        []byte        // node4/node16: parallel with children, sorted
+	children    []*artNode[T] // node4/node16: len==cap; node48: packed, len<=48; node256: len 256, direct indexed
+	childIndex  [256]uint8    // node48 only: childIndex[b]-1 indexes into children; 0 means absent
+}
+
+func newARTLeaf[T any](key string, val T) *artNode[T] {
+	return &artNode[T]{kind: artLeafKind, leaf: &artLeaf[T]{key: key, val: val}}
+}
+
+func (n *artNode[T]) isLeaf() bool {
+	return n.kind == artLeafKind
+}
+
+// setPrefix stores the (possibly truncated) compressed path.
+func (n *artNode[T]) setPrefix(p string) {
+	n.prefixLen = len(p)
+	c := copy(n.prefix[:], p)
+	_ = c
+}
+
+// storedPrefix returns the bytes of the compressed path that are
+// actually stored inline (at most maxPrefixLen).
+func (n *artNode[T]) storedPrefix() []byte {
+	l := n.prefixLen
+	if l > maxPrefixLen {
+		l = maxPrefixLen
+	}
+	return n.prefix[:l]
+}
+
+// minimum returns a leaf reachable from n, used for lazy-expansion
+// comparisons when a node's logical prefix is longer than what is
+// stored inline.
+func (n *artNode[T]) minimum() *artLeaf[T] {
+	cur := n
+	for {
+		if cur.isLeaf() {
+			return cur.leaf
+		}
+		if cur.leaf != nil {
+			return cur.leaf
+		}
+		switch cur.kind {
+		case artNode4, artNode16:
+			cur = cur.children[0]
+		case artNode48:
+			for _, idx := range cur.childIndex {
+				if idx != 0 {
+					cur = cur.children[idx-1]
+					break
+				}
+			}
+		case artNode256:
+			for _, c := range cur.children {
+				if c != nil {
+					cur = c
+					break
+				}
+			}
+		case artLeafKind:
+			return cur.leaf
+		}
+	}
+}
+
+// findChild returns the child stored under label, or nil.
+func (n *artNode[T]) findChild(label byte) *artNode[T] {
+	switch n.kind {
+	case artNode4, artNode16:
+		for i := 0; i < n.numChildren; i++ {
+			if n.keys[i] == label {
+				return n.children[i]
+			}
+		}
+		return nil
+	case artNode48:
+		idx := n.childIndex[label]
+		if idx == 0 {
+			return nil
+		}
+		return n.children[idx-1]
+	case artNode256:
+		return n.children[label]
+	default:
+		return nil
+	}
+}
+
+// addChild inserts a new child under label, growing the node to the
+// next size class first if it is already full.
+func (n *artNode[T]) addChild(parentRef **artNode[T], label byte, child *artNode[T]) { //nolint: cyclop
+	switch n.kind {
+	case artNode4:
+		if n.numChildren < 4 {
+			n.insertSorted(label, child)
+			return
+		}
+		grown := n.grow(artNode16)
+		*parentRef = grown
+		grown.addChild(parentRef, label, child)
+	case artNode16:
+		if n.numChildren < 16 {
+			n.insertSorted(label, child)
+			return
+		}
+		grown := n.grow(artNode48)
+		*parentRef = grown
+		grown.addChild(parentRef, label, child)
+	case artNode48:
+		if n.numChildren < 48 {
+			n.children = append(n.children, child)
+			n.childIndex[label] = uint8(len(n.children))
+			n.numChildren++
+			return
+		}
+		grown := n.grow(artNode256)
+		*parentRef = grown
+		grown.addChild(parentRef, label, child)
+	case artNode256:
+		n.children[label] = child
+		n.numChildren++
+	}
+}
+
+// insertSorted inserts a new (label, child) pair into a node4/node16's
+// parallel arrays, keeping keys sorted so Minimum/Maximum/Walk can
+// rely on array order for iteration.
+func (n *artNode[T]) insertSorted(label byte, child *artNode[T]) {
+	idx := n.numChildren
+	for idx > 0 && n.keys[idx-1] > label {
+		idx--
+	}
+	n.keys = append(n.keys, 0)
+	n.children = append(n.children, nil)
+	copy(n.keys[idx+1:], n.keys[idx:])
+	copy(n.children[idx+1:], n.children[idx:])
+	n.keys[idx] = label
+	n.children[idx] = child
+	n.numChildren++
+}
+
+// grow copies an inner node into the next larger node kind.
+func (n *artNode[T]) grow(to artKind) *artNode[T] { //nolint: cyclop
+	g := &artNode[T]{kind: to, prefixLen: n.prefixLen, prefix: n.prefix, leaf: n.leaf}
+	switch {
+	case n.kind == artNode4 && to == artNode16:
+		g.keys = append([]byte{}, n.keys...)
+		g.children = append([]*artNode[T]{}, n.children...)
+		g.numChildren = n.numChildren
+	case n.kind == artNode16 && to == artNode48:
+		for i := 0; i < n.numChildren; i++ {
+			g.children = append(g.children, n.children[i])
+			g.childIndex[n.keys[i]] = uint8(len(g.children))
+		}
+		g.numChildren = n.numChildren
+	case n.kind == artNode48 && to == artNode256:
+		g.children = make([]*artNode[T], 256)
+		for label, idx := range n.childIndex {
+			if idx != 0 {
+				g.children[label] = n.children[idx-1]
+			}
+		}
+		g.numChildren = n.numChildren
+	}
+	return g
+}
+
+// removeChild deletes the child stored under label, shrinking the
+// node to a smaller size class if it has become sparse, or unlinking
+// it entirely (via parentRef) if it ends up with neither children nor
+// a leaf of its own - otherwise it would stay attached as a dead end
+// whose stale prefixLen could later be compared against by an
+// ancestor's checkPrefix.
+func (n *artNode[T]) removeChild(parentRef **artNode[T], label byte) { //nolint: cyclop
+	switch n.kind {
+	case artNode4, artNode16:
+		for i := 0; i < n.numChildren; i++ {
+			if n.keys[i] == label {
+				n.keys = append(n.keys[:i], n.keys[i+1:]...)
+				n.children = append(n.children[:i], n.children[i+1:]...)
+				n.numChildren--
+				break
+			}
+		}
+		if n.kind == artNode16 && n.numChildren <= 3 {
+			n.unlinkOrShrink(parentRef, artNode4)
+			return
+		}
+	case artNode48:
+		idx := n.childIndex[label]
+		if idx == 0 {
+			return
+		}
+		n.childIndex[label] = 0
+		last := len(n.children) - 1
+		n.children[idx-1] = n.children[last]
+		n.children = n.children[:last]
+		if int(idx-1) != last {
+			for lbl, i := range n.childIndex {
+				if int(i) == last+1 {
+					n.childIndex[lbl] = idx
+					break
+				}
+			}
+		}
+		n.numChildren--
+		if n.numChildren <= 12 {
+			n.unlinkOrShrink(parentRef, artNode16)
+			return
+		}
+	case artNode256:
+		n.children[label] = nil
+		n.numChildren--
+		if n.numChildren <= 37 {
+			n.unlinkOrShrink(parentRef, artNode48)
+			return
+		}
+	}
+	if n.numChildren == 0 && n.leaf == nil {
+		*parentRef = nil
+	}
+}
+
+// unlinkOrShrink replaces *parentRef with n shrunk to the given
+// smaller kind, unless n is now empty (no children and no leaf of its
+// own), in which case it is unlinked entirely (*parentRef = nil)
+// rather than kept alive as a dead end.
+func (n *artNode[T]) unlinkOrShrink(parentRef **artNode[T], to artKind) {
+	if n.numChildren == 0 && n.leaf == nil {
+		*parentRef = nil
+		return
+	}
+	*parentRef = n.shrink(to)
+}
+
+// shrink copies an inner node into the next smaller node kind.
+func (n *artNode[T]) shrink(to artKind) *artNode[T] { //nolint: cyclop
+	s := &artNode[T]{kind: to, prefixLen: n.prefixLen, prefix: n.prefix, leaf: n.leaf}
+	switch {
+	case n.kind == artNode16 && to == artNode4:
+		s.keys = append([]byte{}, n.keys...)
+		s.children = append([]*artNode[T]{}, n.children...)
+		s.numChildren = n.numChildren
+	case n.kind == artNode48 && to == artNode16:
+		for label, idx := range n.childIndex {
+			if idx != 0 {
+				s.keys = append(s.keys, byte(label))
+				s.children = append(s.children, n.children[idx-1])
+			}
+		}
+		s.numChildren = n.numChildren
+	case n.kind == artNode256 && to == artNode48:
+		for label, c := range n.children {
+			if c != nil {
+				s.children = append(s.children, c)
+				s.childIndex[label] = uint8(len(s.children))
+			}
+		}
+		s.numChildren = n.numChildren
+	}
+	return s
+}
+
+// ARTree is an Adaptive Radix Tree. It exposes the same read/write
+// API as Tree[T], but inner nodes adapt their fan-out representation
+// (Node4/16/48/256) to the number of children they actually hold,
+// which gives better cache behavior than Tree[T]'s single edge-slice
+// layout when the keyspace is dense or fan-out is high.
+type ARTree[T any] struct {
+	root *artNode[T]
+	size int
+}
+
+// NewART returns an empty ARTree.
+func NewART[T any]() *ARTree[T] {
+	return &ARTree[T]{}
+}
+
+// NewARTFromMap returns a new ARTree containing the keys from an
+// existing map.
+func NewARTFromMap[T any](m map[string]T) *ARTree[T] {
+	t := NewART[T]()
+	for k, v := range m {
+		t.Insert(k, v)
+	}
+	return t
+}
+
+// Len returns the number of elements in the tree.
+func (t *ARTree[T]) Len() int {
+	return t.size
+}
+
+// checkPrefix compares key[depth:] against n's compressed path,
+// returning the number of matching bytes. When the logical prefix is
+// longer than what is stored inline, the remaining bytes are checked
+// against a representative leaf's full key (lazy expansion).
+func checkPrefix[T any](n *artNode[T], key string, depth int) int {
+	stored := n.storedPrefix()
+	i := 0
+	for ; i < len(stored) && depth+i < len(key); i++ {
+		if key[depth+i] != stored[i] {
+			return i
+		}
+	}
+	if i < n.prefixLen && i == len(stored) {
+		// More prefix than we stored inline: confirm against a leaf.
+		leafKey := n.minimum().key
+		for ; i < n.prefixLen && depth+i < len(key) && depth+i < len(leafKey); i++ {
+			if key[depth+i] != leafKey[depth+i] {
+				return i
+			}
+		}
+	}
+	return i
+}
+
+// Insert adds or updates a key, returning the previous value and
+// whether an existing entry was updated.
+func (t *ARTree[T]) Insert(key string, value T) (existing T, updated bool) {
+	old, isUpdate := t.insert(&t.root, key, 0, value)
+	if !isUpdate {
+		t.size++
+	}
+	return old, isUpdate
+}
+
+func (t *ARTree[T]) insert(ref **artNode[T], key string, depth int, value T) (T, bool) { //nolint: funlen,cyclop
+	n := *ref
+	if n == nil {
+		*ref = newARTLeaf[T](key, value)
+		var zero T
+		return zero, false
+	}
+
+	if n.isLeaf() {
+		if n.leaf.key == key {
+			old := n.leaf.val
+			n.leaf.val = value
+			return old, true
+		}
+		// Split: create an inner node whose prefix is the common
+		// suffix of the two keys from depth onward. Either key (not
+		// just the new one) may end exactly at the split, when one
+		// key is a strict prefix of the other - that key's leaf then
+		// belongs on the split node itself, not on a child edge.
+		common := longestPrefix(key[depth:], n.leaf.key[depth:])
+		split := &artNode[T]{kind: artNode4}
+		split.setPrefix(key[depth : depth+common])
+
+		var existingLabel, newLabel byte
+		var existingHasMore, newHasMore bool
+		if depth+common < len(n.leaf.key) {
+			existingLabel = n.leaf.key[depth+common]
+			existingHasMore = true
+		}
+		if depth+common < len(key) {
+			newLabel = key[depth+common]
+			newHasMore = true
+		}
+
+		splitRef := split
+		if existingHasMore {
+			splitRef.addChild(&splitRef, existingLabel, n)
+		} else {
+			splitRef.leaf = n.leaf
+		}
+		if newHasMore {
+			splitRef.addChild(&splitRef, newLabel, newARTLeaf[T](key, value))
+		} else {
+			splitRef.leaf = &artLeaf[T]{key: key, val: value}
+		}
+		*ref = splitRef
+		var zero T
+		return zero, false
+	}
+
+	if n.prefixLen > 0 {
+		matched := checkPrefix(n, key, depth)
+		if matched < n.prefixLen {
+			// Prefix diverges: split this inner node.
+			split := &artNode[T]{kind: artNode4}
+			split.setPrefix(key[depth : depth+matched])
+
+			oldPrefix := n.fullPrefix(depth)
+			divergingLabel := oldPrefix[matched]
+			n.setPrefix(oldPrefix[matched+1:])
+			splitRef := split
+			splitRef.addChild(&splitRef, divergingLabel, n)
+
+			if depth+matched < len(key) {
+				newLabel := key[depth+matched]
+				splitRef.addChild(&splitRef, newLabel, newARTLeaf[T](key, value))
+			} else {
+				splitRef.leaf = &artLeaf[T]{key: key, val: value}
+			}
+			*ref = splitRef
+			var zero T
+			return zero, false
+		}
+		depth += matched
+	}
+
+	if depth == len(key) {
+		var old T
+		if n.leaf != nil {
+			old = n.leaf.val
+			n.leaf = &artLeaf[T]{key: key, val: value}
+			return old, true
+		}
+		n.leaf = &artLeaf[T]{key: key, val: value}
+		return old, false
+	}
+
+	label := key[depth]
+	child := n.findChild(label)
+	if child == nil {
+		n.addChild(ref, label, newARTLeaf[T](key, value))
+		var zero T
+		return zero, false
+	}
+	childRef := child
+	old, upd := t.insert(&childRef, key, depth+1, value)
+	if childRef != child {
+		n.replaceChild(label, childRef)
+	}
+	return old, upd
+}
+
+// fullPrefix reconstructs the node's full logical prefix (beyond what
+// is stored inline, falling back to a representative leaf).
+func (n *artNode[T]) fullPrefix(depth int) string {
+	if n.prefixLen <= maxPrefixLen {
+		return string(n.storedPrefix())
+	}
+	leafKey := n.minimum().key
+	end := depth + n.prefixLen
+	if end > len(leafKey) {
+		end = len(leafKey)
+	}
+	return leafKey[depth:end]
+}
+
+// replaceChild overwrites the pointer stored under label, used after
+// a recursive call grows/splits a child in place.
+func (n *artNode[T]) replaceChild(label byte, child *artNode[T]) {
+	switch n.kind {
+	case artNode4, artNode16:
+		for i := 0; i < n.numChildren; i++ {
+			if n.keys[i] == label {
+				n.children[i] = child
+				return
+			}
+		}
+	case artNode48:
+		idx := n.childIndex[label]
+		if idx != 0 {
+			n.children[idx-1] = child
+		}
+	case artNode256:
+		n.children[label] = child
+	}
+}
+
+// Get looks up an exact key.
+func (t *ARTree[T]) Get(key string) (value T, found bool) {
+	n := t.root
+	depth := 0
+	for n != nil {
+		if n.isLeaf() {
+			if n.leaf.key == key {
+				return n.leaf.val, true
+			}
+			return
+		}
+		if n.prefixLen > 0 {
+			matched := checkPrefix(n, key, depth)
+			if matched < n.prefixLen {
+				return
+			}
+			depth += matched
+		}
+		if depth == len(key) {
+			if n.leaf != nil {
+				return n.leaf.val, true
+			}
+			return
+		}
+		n = n.findChild(key[depth])
+		depth++
+	}
+	return
+}
+
+// LongestPrefix is like Get, but returns the longest prefix match
+// instead of an exact one.
+func (t *ARTree[T]) LongestPrefix(key string) (matchedKey string, value T, found bool) {
+	var last *artLeaf[T]
+	n := t.root
+	depth := 0
+	for n != nil {
+		if n.isLeaf() {
+			if len(key) >= len(n.leaf.key) && key[:len(n.leaf.key)] == n.leaf.key {
+				last = n.leaf
+			}
+			break
+		}
+		if n.prefixLen > 0 {
+			matched := checkPrefix(n, key, depth)
+			if matched < n.prefixLen {
+				break
+			}
+			depth += matched
+		}
+		// Only once n's whole compressed path has matched is n's own
+		// leaf a prefix of key.
+		if n.leaf != nil {
+			last = n.leaf
+		}
+		if depth == len(key) {
+			break
+		}
+		n = n.findChild(key[depth])
+		depth++
+	}
+	if last != nil {
+		return last.key, last.val, true
+	}
+	return
+}
+
+// Delete removes a key, returning the previous value and whether it
+// was present.
+func (t *ARTree[T]) Delete(key string) (existing T, deleted bool) {
+	old, ok := t.delete(&t.root, key, 0)
+	if ok {
+		t.size--
+	}
+	return old, ok
+}
+
+func (t *ARTree[T]) delete(ref **artNode[T], key string, depth int) (T, bool) { //nolint: cyclop
+	n := *ref
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+	if n.isLeaf() {
+		if n.leaf.key == key {
+			old := n.leaf.val
+			*ref = nil
+			return old, true
+		}
+		var zero T
+		return zero, false
+	}
+	if n.prefixLen > 0 {
+		matched := checkPrefix(n, key, depth)
+		if matched < n.prefixLen {
+			var zero T
+			return zero, false
+		}
+		depth += matched
+	}
+	if depth == len(key) {
+		if n.leaf == nil {
+			var zero T
+			return zero, false
+		}
+		old := n.leaf.val
+		n.leaf = nil
+		if n.numChildren == 0 {
+			// n is now neither a leaf nor an inner node with any
+			// children; unlink it rather than leaving a dead end whose
+			// stale prefixLen could later be compared against by an
+			// ancestor's checkPrefix.
+			*ref = nil
+		}
+		return old, true
+	}
+	label := key[depth]
+	child := n.findChild(label)
+	if child == nil {
+		var zero T
+		return zero, false
+	}
+	childRef := child
+	old, ok := t.delete(&childRef, key, depth+1)
+	if !ok {
+		return old, false
+	}
+	if childRef == nil {
+		n.removeChild(ref, label)
+	} else if childRef != child {
+		n.replaceChild(label, childRef)
+	}
+	return old, true
+}
+
+// Minimum returns the smallest key in the tree.
+func (t *ARTree[T]) Minimum() (key string, value T, found bool) {
+	if t.root == nil {
+		return
+	}
+	leaf := t.root.minimum()
+	return leaf.key, leaf.val, true
+}
+
+// Maximum returns the largest key in the tree.
+func (t *ARTree[T]) Maximum() (key string, value T, found bool) {
+	n := t.root
+	if n == nil {
+		return
+	}
+	for {
+		if n.isLeaf() {
+			return n.leaf.key, n.leaf.val, true
+		}
+		var next *artNode[T]
+		switch n.kind {
+		case artNode4, artNode16:
+			if n.numChildren > 0 {
+				next = n.children[n.numChildren-1]
+			}
+		case artNode48:
+			for label := 255; label >= 0; label-- {
+				if idx := n.childIndex[label]; idx != 0 {
+					next = n.children[idx-1]
+					break
+				}
+			}
+		case artNode256:
+			for label := 255; label >= 0; label-- {
+				if n.children[label] != nil {
+					next = n.children[label]
+					break
+				}
+			}
+		}
+		if next == nil {
+			if n.leaf != nil {
+				return n.leaf.key, n.leaf.val, true
+			}
+			return
+		}
+		n = next
+	}
+}
+
+// Walk visits every key/value in the tree in sorted order.
+func (t *ARTree[T]) Walk(fn WalkFn[T]) {
+	artRecursiveWalk(t.root, fn)
+}
+
+// WalkPrefix visits every key/value under a prefix, in sorted order.
+func (t *ARTree[T]) WalkPrefix(prefix string, fn WalkFn[T]) {
+	n := t.root
+	depth := 0
+	for n != nil {
+		if n.isLeaf() {
+			if len(n.leaf.key) >= len(prefix) && n.leaf.key[:len(prefix)] == prefix {
+				fn(n.leaf.key, n.leaf.val)
+			}
+			return
+		}
+		if depth >= len(prefix) {
+			artRecursiveWalk(n, fn)
+			return
+		}
+		if n.prefixLen > 0 {
+			matched := checkPrefix(n, prefix, depth)
+			want := n.prefixLen
+			if remaining := len(prefix) - depth; remaining < want {
+				want = remaining
+			}
+			if matched < want {
+				return
+			}
+			depth += matched
+			if depth >= len(prefix) {
+				artRecursiveWalk(n, fn)
+				return
+			}
+		}
+		n = n.findChild(prefix[depth])
+		depth++
+	}
+}
+
+func artRecursiveWalk[T any](n *artNode[T], fn WalkFn[T]) bool {
+	if n == nil {
+		return false
+	}
+	if n.isLeaf() {
+		return fn(n.leaf.key, n.leaf.val)
+	}
+	if n.leaf != nil && fn(n.leaf.key, n.leaf.val) {
+		return true
+	}
+	switch n.kind {
+	case artNode4, artNode16:
+		for i := 0; i < n.numChildren; i++ {
+			if artRecursiveWalk(n.children[i], fn) {
+				return true
+			}
+		}
+	case artNode48:
+		for label := 0; label < 256; label++ {
+			if idx := n.childIndex[label]; idx != 0 {
+				if artRecursiveWalk(n.children[idx-1], fn) {
+					return true
+				}
+			}
+		}
+	case artNode256:
+		for _, c := range n.children {
+			if artRecursiveWalk(c, fn) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ToMap walks the tree and converts it into a map.
+func (t *ARTree[T]) ToMap() map[string]T {
+	out := make(map[string]T)
+	t.Walk(func(k string, v T) bool {
+		out[k] = v
+		return false
+	})
+	return out
+}
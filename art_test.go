@@ -0,0 +1,255 @@
+package radix
+
+import "testing"
+
+func TestARTreeInsertGetDelete(t *testing.T) {
+	r := NewART[string]()
+
+	if _, updated := r.Insert("foo", "foo"); updated {
+		t.Fatalf("expected a fresh insert")
+	}
+	if old, updated := r.Insert("foo", "foo2"); !updated || old != "foo" {
+		t.Fatalf("expected update of existing key, got old=%q updated=%v", old, updated)
+	}
+
+	// Each key below extends a key inserted before it ("foo" ->
+	// "foobar" -> "foobarbaz", "g" -> "go"), exercising the
+	// leaf-collision split where the existing leaf's key is a strict
+	// prefix of the new one.
+	r.Insert("foobar", "foobar")
+	r.Insert("foobarbaz", "foobarbaz")
+	r.Insert("g", "g")
+	r.Insert("go", "go")
+
+	want := map[string]string{
+		"foo":       "foo2",
+		"foobar":    "foobar",
+		"foobarbaz": "foobarbaz",
+		"g":         "g",
+		"go":        "go",
+	}
+	for k, wantV := range want {
+		v, ok := r.Get(k)
+		if !ok || v != wantV {
+			t.Fatalf("Get(%q) = %q, %v, want %q, true", k, v, ok, wantV)
+		}
+	}
+	if _, ok := r.Get("missing"); ok {
+		t.Fatalf("expected missing key to be absent")
+	}
+	if r.Len() != len(want) {
+		t.Fatalf("Len() = %d, want %d", r.Len(), len(want))
+	}
+
+	old, deleted := r.Delete("foo")
+	if !deleted || old != "foo2" {
+		t.Fatalf("Delete(foo) = %q, %v, want foo2, true", old, deleted)
+	}
+	if _, ok := r.Get("foo"); ok {
+		t.Fatalf("foo should be gone after delete")
+	}
+	if v, ok := r.Get("foobar"); !ok || v != "foobar" {
+		t.Fatalf("deleting foo should not disturb foobar, got %q, %v", v, ok)
+	}
+}
+
+func TestARTreeInsertExistingKeyIsPrefixOfNew(t *testing.T) {
+	// Reproduces the panic fixed alongside this test: inserting a longer
+	// key when an existing leaf's key is a strict prefix of it must put
+	// that existing leaf in the split node's own leaf slot rather than
+	// indexing past the end of the shorter key.
+	r := NewART[string]()
+	r.Insert("foo", "foo")
+	r.Insert("foobar", "foobar")
+
+	for _, k := range []string{"foo", "foobar"} {
+		v, ok := r.Get(k)
+		if !ok || v != k {
+			t.Fatalf("Get(%q) = %q, %v, want %q, true", k, v, ok, k)
+		}
+	}
+}
+
+func TestARTreeDeleteChurnLongSharedPrefix(t *testing.T) {
+	// Reproduces a panic found during review: keys sharing a prefix
+	// longer than maxPrefixLen leave inner nodes relying on lazy
+	// expansion (prefixLen > len(storedPrefix)). Deleting all of them
+	// must not leave a childless, leaf-less node attached under a
+	// stale prefixLen, which a later checkPrefix on an ancestor would
+	// fall back to minimum() on and panic indexing into an empty
+	// children slice.
+	r := NewART[int]()
+	keys := []string{
+		"this-is-a-very-long-shared-prefix-that-exceeds-ten-bytesbacabaaaccbbacabacb",
+		"this-is-a-very-long-shared-prefix-that-cbbcabbcacbab",
+		"this-is-a-very-long-shared-prefix-that-exceeds-ten-abbbbbab",
+		"this-is-a-very-long-shared-prefix-thataccbcabaccbb",
+	}
+	for i, k := range keys {
+		r.Insert(k, i)
+	}
+	for _, k := range keys {
+		if _, deleted := r.Delete(k); !deleted {
+			t.Fatalf("Delete(%q) = false, want true", k)
+		}
+	}
+	if r.Len() != 0 {
+		t.Fatalf("Len() = %d after deleting every key, want 0", r.Len())
+	}
+	if r.root != nil {
+		t.Fatalf("root = %v after deleting every key, want nil", r.root)
+	}
+}
+
+func TestARTreeDeleteChurnRepeatedInsertDelete(t *testing.T) {
+	// Broader delete-churn coverage for the same class of bug: repeated
+	// insert/delete cycles of keys sharing a prefix longer than
+	// maxPrefixLen, in varying orders, should never panic and should
+	// always leave Get/Len consistent.
+	prefix := "another-very-long-shared-prefix-well-past-the-inline-limit-"
+	suffixes := []string{"aaa", "aab", "aba", "baa", "bbb", "abc", "bca"}
+
+	for round := 0; round < len(suffixes); round++ {
+		r := NewART[int]()
+		keys := make([]string, len(suffixes))
+		for i, s := range suffixes {
+			keys[i] = prefix + s
+		}
+		for i, k := range keys {
+			r.Insert(k, i)
+		}
+		// Delete starting from a different offset each round so the
+		// deletion order varies relative to insertion order.
+		for i := 0; i < len(keys); i++ {
+			k := keys[(i+round)%len(keys)]
+			if _, deleted := r.Delete(k); !deleted {
+				t.Fatalf("round %d: Delete(%q) = false, want true", round, k)
+			}
+			if _, ok := r.Get(k); ok {
+				t.Fatalf("round %d: Get(%q) found after delete", round, k)
+			}
+		}
+		if r.Len() != 0 {
+			t.Fatalf("round %d: Len() = %d after deleting every key, want 0", round, r.Len())
+		}
+	}
+}
+
+func TestARTreeGrowthThresholds(t *testing.T) {
+	// Inserting keys that differ only in their last byte under a
+	// shared prefix all land as children of the same inner node, so
+	// growing that node exercises each adaptive size-class transition
+	// in turn.
+	r := NewART[int]()
+	prefix := "k"
+	suffix := func(i int) string { return string([]byte{byte(i)}) }
+
+	check := func(n int, want artKind) {
+		if r.root.kind != want {
+			t.Fatalf("after %d children, root.kind = %v, want %v", n, r.root.kind, want)
+		}
+	}
+
+	for i := 0; i < 4; i++ {
+		r.Insert(prefix+suffix(i), i)
+	}
+	check(4, artNode4)
+
+	r.Insert(prefix+suffix(4), 4)
+	check(5, artNode16)
+
+	for i := 5; i < 16; i++ {
+		r.Insert(prefix+suffix(i), i)
+	}
+	check(16, artNode16)
+
+	r.Insert(prefix+suffix(16), 16)
+	check(17, artNode48)
+
+	for i := 17; i < 48; i++ {
+		r.Insert(prefix+suffix(i), i)
+	}
+	check(48, artNode48)
+
+	r.Insert(prefix+suffix(48), 48)
+	check(49, artNode256)
+}
+
+func TestARTreeShrinkThresholds(t *testing.T) {
+	// Mirrors TestARTreeGrowthThresholds, but grows a node all the way
+	// to artNode256 first and then deletes children one at a time,
+	// asserting the node shrinks back down through each size class at
+	// the documented thresholds.
+	r := NewART[int]()
+	prefix := "k"
+	suffix := func(i int) string { return string([]byte{byte(i)}) }
+
+	const total = 49 // forces artNode256, per TestARTreeGrowthThresholds
+	for i := 0; i < total; i++ {
+		r.Insert(prefix+suffix(i), i)
+	}
+	if r.root.kind != artNode256 {
+		t.Fatalf("root.kind = %v after %d inserts, want artNode256", r.root.kind, total)
+	}
+
+	del := func(i int) {
+		k := prefix + suffix(i)
+		if _, deleted := r.Delete(k); !deleted {
+			t.Fatalf("Delete(%q) = false, want true", k)
+		}
+	}
+
+	// artNode256 shrinks to artNode48 once numChildren <= 37, i.e.
+	// after removing children down to 37 remaining.
+	for i := total - 1; i >= 37; i-- {
+		del(i)
+	}
+	if r.root.kind != artNode48 {
+		t.Fatalf("root.kind = %v with 37 children left, want artNode48", r.root.kind)
+	}
+
+	// artNode48 shrinks to artNode16 once numChildren <= 12.
+	for i := 36; i >= 12; i-- {
+		del(i)
+	}
+	if r.root.kind != artNode16 {
+		t.Fatalf("root.kind = %v with 12 children left, want artNode16", r.root.kind)
+	}
+
+	// artNode16 shrinks to artNode4 once numChildren <= 3.
+	for i := 11; i >= 3; i-- {
+		del(i)
+	}
+	if r.root.kind != artNode4 {
+		t.Fatalf("root.kind = %v with 3 children left, want artNode4", r.root.kind)
+	}
+
+	for i := 2; i >= 0; i-- {
+		del(i)
+	}
+	if r.root != nil {
+		t.Fatalf("root = %v after deleting every child, want nil", r.root)
+	}
+}
+
+func TestARTreeWalk(t *testing.T) {
+	r := NewART[int]()
+	keys := []string{"a", "ab", "abc", "b"}
+	for _, k := range keys {
+		r.Insert(k, len(k))
+	}
+
+	seen := map[string]int{}
+	r.Walk(func(k string, v int) bool {
+		seen[k] = v
+		return false
+	})
+	if len(seen) != len(keys) {
+		t.Fatalf("Walk visited %d keys, want %d", len(seen), len(keys))
+	}
+	for _, k := range keys {
+		if seen[k] != len(k) {
+			t.Fatalf("Walk value for %q = %d, want %d", k, seen[k], len(k))
+		}
+	}
+}
@@ -78,3 +78,62 @@ func BenchmarkLongestPrefix(b *testing.B) {
 		}
 	}
 }
+
+// createARTree builds the same corpus as createTree, but backed by
+// ARTree, so the two implementations can be benchmarked head to head.
+func createARTree() (*ARTree[string], []string) {
+	r := NewART[string]()
+	paths := make([]string, 0)
+	for c := 'a'; c < 'i'; c++ {
+		for _, path := range suffixes {
+			paths = append(paths, string(c)+path)
+		}
+	}
+
+	for _, path := range paths {
+		r.Insert(path, path)
+	}
+	return r, paths
+}
+
+func BenchmarkARTInsert(b *testing.B) {
+	r, paths := createARTree()
+	b.ResetTimer()
+	for n := range b.N {
+		for _, path := range paths {
+			r.Insert(path+strconv.Itoa(n), path)
+		}
+	}
+}
+
+func BenchmarkARTGet(b *testing.B) {
+	r, paths := createARTree()
+	b.ResetTimer()
+	for range b.N {
+		for _, path := range paths {
+			actual, ok := r.Get(path)
+			if !ok {
+				b.Fatalf("Expected %s, got nothing", path)
+			}
+			if actual != path {
+				b.Fatalf("Expected %s, got %s", path, actual)
+			}
+		}
+	}
+}
+
+func BenchmarkARTLongestPrefix(b *testing.B) {
+	r, paths := createARTree()
+	b.ResetTimer()
+	for range b.N {
+		for _, path := range paths {
+			actual, _, ok := r.LongestPrefix(path)
+			if !ok {
+				b.Fatalf("Expected %s, got nothing", path)
+			}
+			if actual != path {
+				b.Fatalf("Expected %s, got %s", path, actual)
+			}
+		}
+	}
+}
@@ -0,0 +1,499 @@
+package radix
+
+// Key abstracts over the byte sequence used to index a TreeBytes,
+// letting callers store keys that are not naturally strings -
+// big-endian integers, IP addresses/CIDRs, MAC prefixes, or composite
+// tuples - without an intermediate allocation to convert them to
+// string or []byte. ByteSlice implements Key for plain []byte keys.
+//
+// Slice returns a Key, not a concrete type, so every call boxes its
+// result - for ByteSlice specifically, a 3-word slice header doesn't
+// fit in an interface value's single data word, so it escapes to the
+// heap. A caller that walks a tree by repeatedly calling Slice once
+// per level (as TreeBytes itself used to) pays one allocation per
+// level of descent. TreeBytes's own Insert/Get/Delete/LongestPrefix/
+// WalkPrefix avoid this by tracking a (Key, offset) pair internally
+// and only calling Slice when a sub-key must actually be persisted
+// (as a node's prefix or a new leaf's key); callers walking a Key
+// directly should consider the same pattern rather than Slice-ing on
+// every step.
+type Key interface {
+	// Len returns the number of bytes in the key.
+	Len() int
+	// At returns the byte at position i.
+	At(i int) byte
+	// Slice returns the sub-key [lo, hi).
+	Slice(lo, hi int) Key
+}
+
+// ByteSlice is a Key backed directly by a []byte, with no copying on
+// Slice - the returned Key shares the underlying array.
+type ByteSlice []byte
+
+// Len implements Key.
+func (b ByteSlice) Len() int { return len(b) }
+
+// At implements Key.
+func (b ByteSlice) At(i int) byte { return b[i] }
+
+// Slice implements Key.
+func (b ByteSlice) Slice(lo, hi int) Key { return b[lo:hi] }
+
+// keyLongestPrefix returns the length of the shared prefix of a and b.
+func keyLongestPrefix(a, b Key) int {
+	longest := a.Len()
+	if l := b.Len(); l < longest {
+		longest = l
+	}
+	var i int
+	for i = 0; i < longest; i++ {
+		if a.At(i) != b.At(i) {
+			break
+		}
+	}
+	return i
+}
+
+// keyLongestPrefixAt is keyLongestPrefix(a.Slice(aOff, a.Len()), b),
+// without the Slice call - see Key's doc comment.
+func keyLongestPrefixAt(a Key, aOff int, b Key) int {
+	longest := a.Len() - aOff
+	if l := b.Len(); l < longest {
+		longest = l
+	}
+	var i int
+	for i = 0; i < longest; i++ {
+		if a.At(aOff+i) != b.At(i) {
+			break
+		}
+	}
+	return i
+}
+
+// keyHasPrefixAt is keyHasPrefix(k.Slice(kOff, k.Len()), prefix),
+// without the Slice call - see Key's doc comment.
+func keyHasPrefixAt(k Key, kOff int, prefix Key) bool {
+	if prefix.Len() > k.Len()-kOff {
+		return false
+	}
+	for i := 0; i < prefix.Len(); i++ {
+		if k.At(kOff+i) != prefix.At(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// keyHasPrefixOfAt is keyHasPrefix(k, prefixSrc.Slice(prefixOff,
+// prefixSrc.Len())), without the Slice call - see Key's doc comment.
+func keyHasPrefixOfAt(k, prefixSrc Key, prefixOff int) bool {
+	prefixLen := prefixSrc.Len() - prefixOff
+	if prefixLen > k.Len() {
+		return false
+	}
+	for i := 0; i < prefixLen; i++ {
+		if k.At(i) != prefixSrc.At(prefixOff+i) {
+			return false
+		}
+	}
+	return true
+}
+
+// keyHasPrefix reports whether k starts with prefix.
+func keyHasPrefix(k, prefix Key) bool {
+	if prefix.Len() > k.Len() {
+		return false
+	}
+	for i := 0; i < prefix.Len(); i++ {
+		if k.At(i) != prefix.At(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// bytesLeaf is used to represent a value in a TreeBytes.
+type bytesLeaf[T any] struct {
+	key Key
+	val T
+}
+
+// bytesEdge is used to represent an edge node in a TreeBytes.
+type bytesEdge[T any] struct {
+	label byte
+	node  *bytesNode[T]
+}
+
+type bytesNode[T any] struct {
+	leaf   *bytesLeaf[T]
+	prefix Key
+	edges  bytesEdges[T]
+}
+
+func (n *bytesNode[T]) isLeaf() bool {
+	return n.leaf != nil
+}
+
+func (n *bytesNode[T]) addEdge(e bytesEdge[T]) {
+	num := len(n.edges)
+	idx := sortSearch(num, func(i int) bool {
+		return n.edges[i].label >= e.label
+	})
+
+	n.edges = append(n.edges, bytesEdge[T]{})
+	copy(n.edges[idx+1:], n.edges[idx:])
+	n.edges[idx] = e
+}
+
+func (n *bytesNode[T]) updateEdge(label byte, node *bytesNode[T]) {
+	num := len(n.edges)
+	idx := sortSearch(num, func(i int) bool {
+		return n.edges[i].label >= label
+	})
+	if idx < num && n.edges[idx].label == label {
+		n.edges[idx].node = node
+		return
+	}
+	panic("replacing missing edge")
+}
+
+func (n *bytesNode[T]) getEdge(label byte) *bytesNode[T] {
+	num := len(n.edges)
+	idx := sortSearch(num, func(i int) bool {
+		return n.edges[i].label >= label
+	})
+	if idx < num && n.edges[idx].label == label {
+		return n.edges[idx].node
+	}
+	return nil
+}
+
+func (n *bytesNode[T]) delEdge(label byte) {
+	num := len(n.edges)
+	idx := sortSearch(num, func(i int) bool {
+		return n.edges[i].label >= label
+	})
+	if idx < num && n.edges[idx].label == label {
+		copy(n.edges[idx:], n.edges[idx+1:])
+		n.edges[len(n.edges)-1] = bytesEdge[T]{}
+		n.edges = n.edges[:len(n.edges)-1]
+	}
+}
+
+func (n *bytesNode[T]) mergeChild() {
+	e := n.edges[0]
+	child := e.node
+	n.prefix = concatKey(n.prefix, child.prefix)
+	n.leaf = child.leaf
+	n.edges = child.edges
+}
+
+func concatKey(a, b Key) Key {
+	out := make(ByteSlice, a.Len()+b.Len())
+	for i := 0; i < a.Len(); i++ {
+		out[i] = a.At(i)
+	}
+	for i := 0; i < b.Len(); i++ {
+		out[a.Len()+i] = b.At(i)
+	}
+	return out
+}
+
+type bytesEdges[T any] []bytesEdge[T]
+
+// TreeBytes is a radix tree keyed by Key instead of string, avoiding
+// the string<->[]byte conversion allocation on hot paths such as
+// router lookups, and allowing binary keys (IP CIDRs, MAC prefixes,
+// encoded timestamps) to be stored directly. Its API mirrors Tree[T];
+// Tree[T] itself is left untouched as the string-keyed default.
+type TreeBytes[T any] struct {
+	root *bytesNode[T]
+	size int
+}
+
+// NewBytes returns an empty TreeBytes.
+func NewBytes[T any]() *TreeBytes[T] {
+	return &TreeBytes[T]{root: &bytesNode[T]{}}
+}
+
+// Len returns the number of elements in the tree.
+func (t *TreeBytes[T]) Len() int {
+	return t.size
+}
+
+// Insert is used to add a new entry or update an existing entry.
+// Returns the previous value and true if an existing record was
+// updated.
+func (t *TreeBytes[T]) Insert(key Key, value T) (existing T, updated bool) { //nolint: funlen
+	var parent *bytesNode[T]
+	current := t.root
+	// searchOff is how far into key the search has advanced; tracking
+	// it as a plain int instead of re-slicing key at every level
+	// avoids boxing a new Key on each step - see Key's doc comment.
+	searchOff := 0
+	for {
+		searchLen := key.Len() - searchOff
+		if searchLen == 0 {
+			if current.isLeaf() {
+				old := current.leaf.val
+				current.leaf.val = value
+				return old, true
+			}
+			current.leaf = &bytesLeaf[T]{key: key, val: value}
+			t.size++
+			var zero T
+			return zero, false
+		}
+
+		parent = current
+		current = current.getEdge(key.At(searchOff))
+
+		if current == nil {
+			e := bytesEdge[T]{
+				label: key.At(searchOff),
+				node: &bytesNode[T]{
+					leaf:   &bytesLeaf[T]{key: key, val: value},
+					prefix: key.Slice(searchOff, key.Len()),
+				},
+			}
+			parent.addEdge(e)
+			t.size++
+			var zero T
+			return zero, false
+		}
+
+		commonPrefix := keyLongestPrefixAt(key, searchOff, current.prefix)
+		if commonPrefix == current.prefix.Len() {
+			searchOff += commonPrefix
+			continue
+		}
+
+		t.size++
+		child := &bytesNode[T]{
+			prefix: key.Slice(searchOff, searchOff+commonPrefix),
+		}
+		parent.updateEdge(key.At(searchOff), child)
+
+		child.addEdge(bytesEdge[T]{
+			label: current.prefix.At(commonPrefix),
+			node:  current,
+		})
+		current.prefix = current.prefix.Slice(commonPrefix, current.prefix.Len())
+
+		leaf := &bytesLeaf[T]{key: key, val: value}
+
+		searchOff += commonPrefix
+		if searchOff == key.Len() {
+			child.leaf = leaf
+			var zero T
+			return zero, false
+		}
+
+		child.addEdge(bytesEdge[T]{
+			label: key.At(searchOff),
+			node: &bytesNode[T]{
+				leaf:   leaf,
+				prefix: key.Slice(searchOff, key.Len()),
+			},
+		})
+		var zero T
+		return zero, false
+	}
+}
+
+// Delete is used to delete a key, returning the previous value and
+// if it was deleted.
+func (t *TreeBytes[T]) Delete(key Key) (existing T, deleted bool) { //nolint: cyclop
+	var parent *bytesNode[T]
+	var label byte
+	current := t.root
+	searchOff := 0
+	for {
+		if searchOff == key.Len() {
+			if !current.isLeaf() {
+				var zero T
+				return zero, false
+			}
+			goto DELETE
+		}
+
+		parent = current
+		label = key.At(searchOff)
+		current = current.getEdge(label)
+		if current == nil {
+			var zero T
+			return zero, false
+		}
+
+		if keyHasPrefixAt(key, searchOff, current.prefix) {
+			searchOff += current.prefix.Len()
+		} else {
+			var zero T
+			return zero, false
+		}
+	}
+
+DELETE:
+	leaf := current.leaf
+	current.leaf = nil
+	t.size--
+
+	if parent != nil && len(current.edges) == 0 {
+		parent.delEdge(label)
+	}
+
+	if current != t.root && len(current.edges) == 1 {
+		current.mergeChild()
+	}
+
+	if parent != nil && parent != t.root && len(parent.edges) == 1 && !parent.isLeaf() {
+		parent.mergeChild()
+	}
+
+	return leaf.val, true
+}
+
+// Get is used to look up a specific key, returning the value and if
+// it was found.
+func (t *TreeBytes[T]) Get(key Key) (value T, found bool) {
+	current := t.root
+	searchOff := 0
+	for {
+		if searchOff == key.Len() {
+			if current.isLeaf() {
+				return current.leaf.val, true
+			}
+			break
+		}
+		current = current.getEdge(key.At(searchOff))
+		if current == nil {
+			break
+		}
+		if keyHasPrefixAt(key, searchOff, current.prefix) {
+			searchOff += current.prefix.Len()
+		} else {
+			break
+		}
+	}
+	return
+}
+
+// LongestPrefix is like Get, but instead of an exact match, it
+// returns the longest prefix match.
+func (t *TreeBytes[T]) LongestPrefix(key Key) (matchedKey Key, value T, found bool) {
+	var last *bytesLeaf[T]
+	current := t.root
+	searchOff := 0
+	for {
+		if current.isLeaf() {
+			last = current.leaf
+		}
+		if searchOff == key.Len() {
+			break
+		}
+		current = current.getEdge(key.At(searchOff))
+		if current == nil {
+			break
+		}
+		if keyHasPrefixAt(key, searchOff, current.prefix) {
+			searchOff += current.prefix.Len()
+		} else {
+			break
+		}
+	}
+	if last != nil {
+		return last.key, last.val, true
+	}
+	return
+}
+
+// Minimum is used to return the minimum value in the tree.
+func (t *TreeBytes[T]) Minimum() (key Key, value T, found bool) {
+	current := t.root
+	for {
+		if current.isLeaf() {
+			return current.leaf.key, current.leaf.val, true
+		}
+		if len(current.edges) > 0 {
+			current = current.edges[0].node
+		} else {
+			break
+		}
+	}
+	return
+}
+
+// Maximum is used to return the maximum value in the tree.
+func (t *TreeBytes[T]) Maximum() (key Key, value T, found bool) {
+	current := t.root
+	for {
+		if num := len(current.edges); num > 0 {
+			current = current.edges[num-1].node
+			continue
+		}
+		if current.isLeaf() {
+			return current.leaf.key, current.leaf.val, true
+		}
+		break
+	}
+	return
+}
+
+// BytesWalkFn is used when walking a TreeBytes.
+type BytesWalkFn[T any] func(k Key, v T) bool
+
+// Walk is used to walk the tree.
+func (t *TreeBytes[T]) Walk(fn BytesWalkFn[T]) {
+	bytesRecursiveWalk(t.root, fn)
+}
+
+// WalkPrefix is used to walk the tree under a prefix.
+func (t *TreeBytes[T]) WalkPrefix(prefix Key, fn BytesWalkFn[T]) {
+	current := t.root
+	searchOff := 0
+	for {
+		if searchOff == prefix.Len() {
+			bytesRecursiveWalk(current, fn)
+			return
+		}
+		current = current.getEdge(prefix.At(searchOff))
+		if current == nil {
+			return
+		}
+		if keyHasPrefixAt(prefix, searchOff, current.prefix) {
+			searchOff += current.prefix.Len()
+			continue
+		}
+		if keyHasPrefixOfAt(current.prefix, prefix, searchOff) {
+			bytesRecursiveWalk(current, fn)
+		}
+		return
+	}
+}
+
+func bytesRecursiveWalk[T any](current *bytesNode[T], fn BytesWalkFn[T]) bool {
+	if current.leaf != nil && fn(current.leaf.key, current.leaf.val) {
+		return true
+	}
+	for _, e := range current.edges {
+		if bytesRecursiveWalk(e.node, fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToMap is used to walk the tree and convert it into a map keyed by
+// the string form of each Key's bytes.
+func (t *TreeBytes[T]) ToMap() map[string]T {
+	out := make(map[string]T, t.size)
+	t.Walk(func(k Key, v T) bool {
+		buf := make([]byte, k.Len())
+		for i := range buf {
+			buf[i] = k.At(i)
+		}
+		out[string(buf)] = v
+		return false
+	})
+	return out
+}
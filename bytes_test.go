@@ -0,0 +1,108 @@
+package radix
+
+import "testing"
+
+func TestTreeBytesInsertGetDelete(t *testing.T) {
+	tree := NewBytes[string]()
+
+	if _, updated := tree.Insert(ByteSlice("foo"), "foo"); updated {
+		t.Fatalf("expected a fresh insert")
+	}
+	if old, updated := tree.Insert(ByteSlice("foo"), "foo2"); !updated || old != "foo" {
+		t.Fatalf("expected update of existing key, got old=%q updated=%v", old, updated)
+	}
+
+	// "foobar" has "foo" as a strict prefix, and "foobarbaz" has
+	// "foobar" as a strict prefix - exercises inserting a key that an
+	// existing key is a strict prefix of.
+	tree.Insert(ByteSlice("foobar"), "foobar")
+	tree.Insert(ByteSlice("foobarbaz"), "foobarbaz")
+	tree.Insert(ByteSlice("g"), "g")
+	tree.Insert(ByteSlice("go"), "go")
+
+	want := map[string]string{
+		"foo":       "foo2",
+		"foobar":    "foobar",
+		"foobarbaz": "foobarbaz",
+		"g":         "g",
+		"go":        "go",
+	}
+	for k, wantV := range want {
+		v, ok := tree.Get(ByteSlice(k))
+		if !ok || v != wantV {
+			t.Fatalf("Get(%q) = %q, %v, want %q, true", k, v, ok, wantV)
+		}
+	}
+	if _, ok := tree.Get(ByteSlice("missing")); ok {
+		t.Fatalf("expected missing key to be absent")
+	}
+	if tree.Len() != len(want) {
+		t.Fatalf("Len() = %d, want %d", tree.Len(), len(want))
+	}
+
+	old, deleted := tree.Delete(ByteSlice("foo"))
+	if !deleted || old != "foo2" {
+		t.Fatalf("Delete(foo) = %q, %v, want foo2, true", old, deleted)
+	}
+	if _, ok := tree.Get(ByteSlice("foo")); ok {
+		t.Fatalf("foo should be gone after delete")
+	}
+	if v, ok := tree.Get(ByteSlice("foobar")); !ok || v != "foobar" {
+		t.Fatalf("deleting foo should not disturb foobar, got %q, %v", v, ok)
+	}
+}
+
+func TestTreeBytesLongestPrefix(t *testing.T) {
+	tree := NewBytes[string]()
+	tree.Insert(ByteSlice("foo"), "foo")
+	tree.Insert(ByteSlice("foobar"), "foobar")
+
+	k, v, ok := tree.LongestPrefix(ByteSlice("foobarbaz"))
+	if !ok || string(k.(ByteSlice)) != "foobar" || v != "foobar" {
+		t.Fatalf("LongestPrefix(foobarbaz) = %v, %q, %v, want foobar, foobar, true", k, v, ok)
+	}
+}
+
+func TestTreeBytesWalk(t *testing.T) {
+	tree := NewBytes[int]()
+	keys := []string{"a", "ab", "abc", "b"}
+	for _, k := range keys {
+		tree.Insert(ByteSlice(k), len(k))
+	}
+
+	seen := map[string]int{}
+	tree.Walk(func(k Key, v int) bool {
+		seen[string(k.(ByteSlice))] = v
+		return false
+	})
+	if len(seen) != len(keys) {
+		t.Fatalf("Walk visited %d keys, want %d", len(seen), len(keys))
+	}
+	for _, k := range keys {
+		if seen[k] != len(k) {
+			t.Fatalf("Walk value for %q = %d, want %d", k, seen[k], len(k))
+		}
+	}
+}
+
+func TestTreeBytesWalkPrefix(t *testing.T) {
+	tree := NewBytes[string]()
+	for _, k := range []string{"foo", "foobar", "foobarbaz", "foe", "bar"} {
+		tree.Insert(ByteSlice(k), k)
+	}
+
+	seen := map[string]bool{}
+	tree.WalkPrefix(ByteSlice("foob"), func(k Key, v string) bool {
+		seen[v] = true
+		return false
+	})
+	want := map[string]bool{"foobar": true, "foobarbaz": true}
+	if len(seen) != len(want) {
+		t.Fatalf("WalkPrefix(foob) visited %v, want %v", seen, want)
+	}
+	for k := range want {
+		if !seen[k] {
+			t.Fatalf("WalkPrefix(foob) missing %q, got %v", k, seen)
+		}
+	}
+}
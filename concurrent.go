@@ -0,0 +1,179 @@
+package radix
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ConcurrentTree wraps an immutable radix tree so it can be shared
+// across goroutines without callers taking their own lock. Writers
+// are serialized by an internal mutex; readers never block on it,
+// since every read simply follows an atomically-loaded pointer to an
+// ITree[T] root that, once published, is never mutated.
+type ConcurrentTree[T any] struct {
+	mu   sync.Mutex
+	root atomic.Pointer[ITree[T]]
+}
+
+// NewConcurrent returns an empty ConcurrentTree.
+func NewConcurrent[T any]() *ConcurrentTree[T] {
+	ct := &ConcurrentTree[T]{}
+	ct.root.Store(NewI[T]())
+	return ct
+}
+
+// Len returns the number of elements in the tree.
+func (ct *ConcurrentTree[T]) Len() int {
+	return ct.root.Load().Len()
+}
+
+// Get looks up a specific key.
+func (ct *ConcurrentTree[T]) Get(k string) (value T, found bool) {
+	return ct.root.Load().Get(k)
+}
+
+// LongestPrefix is like Get, but returns the longest prefix match.
+func (ct *ConcurrentTree[T]) LongestPrefix(k string) (key string, value T, found bool) {
+	return ct.root.Load().LongestPrefix(k)
+}
+
+// Insert adds or updates a key, returning the previous value and
+// whether an existing entry was updated.
+func (ct *ConcurrentTree[T]) Insert(k string, v T) (existing T, updated bool) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	newTree, old, upd := ct.root.Load().Insert(k, v)
+	ct.root.Store(newTree)
+	return old, upd
+}
+
+// Delete removes a key, returning the previous value and whether it
+// was present.
+func (ct *ConcurrentTree[T]) Delete(k string) (existing T, deleted bool) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	newTree, old, del := ct.root.Load().Delete(k)
+	ct.root.Store(newTree)
+	return old, del
+}
+
+// Walk walks the current tree in sorted order.
+func (ct *ConcurrentTree[T]) Walk(fn WalkFn[T]) {
+	ct.root.Load().Walk(fn)
+}
+
+// WalkPrefix walks the current tree under a prefix.
+func (ct *ConcurrentTree[T]) WalkPrefix(prefix string, fn WalkFn[T]) {
+	ct.root.Load().WalkPrefix(prefix, fn)
+}
+
+// Snapshot returns a read-only, point-in-time view of the tree. The
+// snapshot is unaffected by writes made through ct after the call
+// returns, and can be walked or queried from any goroutine without
+// synchronization, since ITree[T] is itself immutable.
+func (ct *ConcurrentTree[T]) Snapshot() *ITree[T] {
+	return ct.root.Load()
+}
+
+// RangeSnapshot iterates the keys in [fromKey, toKey) over a
+// Snapshot(), in ascending lexicographic order. Unlike WalkPrefix,
+// the bounds need not share a common prefix, and the walk stops as
+// soon as it passes toKey rather than requiring callers to filter a
+// full-tree Walk themselves.
+func (ct *ConcurrentTree[T]) RangeSnapshot(fromKey, toKey string, fn WalkFn[T]) {
+	ct.Snapshot().RangeSnapshot(fromKey, toKey, fn)
+}
+
+// RangeSnapshot iterates the keys in [fromKey, toKey) held by this
+// immutable tree, in ascending lexicographic order. It descends
+// directly to fromKey - the same technique WalkPrefix and
+// Iterator.SeekLowerBound use to avoid a key comparison per node - and
+// stops the walk as soon as it reaches toKey, rather than filtering a
+// full Walk of the tree.
+func (t *ITree[T]) RangeSnapshot(fromKey, toKey string, fn WalkFn[T]) {
+	stack := iSeekLowerBound(t.root, fromKey)
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if !top.leafDone {
+			top.leafDone = true
+			if top.node.isLeaf() {
+				k := top.node.leaf.key
+				if k >= toKey {
+					return
+				}
+				if fn(k, top.node.leaf.val) {
+					return
+				}
+			}
+		}
+		if top.edgeIdx < len(top.node.edges) {
+			child := top.node.edges[top.edgeIdx].node
+			top.edgeIdx++
+			stack = append(stack, irangeFrame[T]{node: child})
+			continue
+		}
+		stack = stack[:len(stack)-1]
+	}
+}
+
+// irangeFrame is one level of the explicit descent stack RangeSnapshot
+// resumes from after iSeekLowerBound - the Node[T] counterpart of
+// iterFrame, which does the same job for Tree[T]'s Iterator.
+type irangeFrame[T any] struct {
+	node     *Node[T]
+	leafDone bool
+	edgeIdx  int
+}
+
+// iSeekLowerBound builds the descent stack for the first key >= search
+// in n's subtree, in ascending order - the Node[T] counterpart of
+// Iterator.SeekLowerBound's manual descent.
+func iSeekLowerBound[T any](n *Node[T], search string) []irangeFrame[T] { //nolint: cyclop
+	var stack []irangeFrame[T]
+	for {
+		if n == nil {
+			return nil
+		}
+		if len(search) == 0 {
+			// Every key in this subtree is >= search.
+			stack = append(stack, irangeFrame[T]{node: n})
+			return stack
+		}
+
+		idx := sortSearch(len(n.edges), func(i int) bool {
+			return n.edges[i].label >= search[0]
+		})
+		// n's own leaf (if any) represents a strictly shorter key than
+		// search sharing everything matched so far, so it is always <
+		// search here; skip it.
+		stack = append(stack, irangeFrame[T]{node: n, leafDone: true, edgeIdx: idx})
+
+		if idx == len(n.edges) {
+			return stack
+		}
+		child := n.edges[idx].node
+
+		switch {
+		case child.prefix[0] > search[0]:
+			return stack
+		case strings.HasPrefix(search, child.prefix):
+			// About to descend into child, so the frame just pushed
+			// for n must resume just past it.
+			stack[len(stack)-1].edgeIdx = idx + 1
+			search = search[len(child.prefix):]
+			n = child
+			continue
+		case strings.HasPrefix(child.prefix, search):
+			return stack
+		default:
+			common := longestPrefix(search, child.prefix)
+			if child.prefix[common] > search[common] {
+				return stack
+			}
+			// child's subtree is entirely < search; skip past it.
+			stack[len(stack)-1].edgeIdx = idx + 1
+			return stack
+		}
+	}
+}
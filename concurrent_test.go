@@ -0,0 +1,134 @@
+package radix
+
+import "testing"
+
+func TestConcurrentTreeInsertGetDelete(t *testing.T) {
+	ct := NewConcurrent[string]()
+
+	if _, updated := ct.Insert("foo", "foo"); updated {
+		t.Fatalf("expected a fresh insert")
+	}
+	if old, updated := ct.Insert("foo", "foo2"); !updated || old != "foo" {
+		t.Fatalf("expected update of existing key, got old=%q updated=%v", old, updated)
+	}
+	ct.Insert("foobar", "foobar")
+	ct.Insert("foobarbaz", "foobarbaz")
+	ct.Insert("g", "g")
+	ct.Insert("go", "go")
+
+	want := map[string]string{
+		"foo":       "foo2",
+		"foobar":    "foobar",
+		"foobarbaz": "foobarbaz",
+		"g":         "g",
+		"go":        "go",
+	}
+	for k, wantV := range want {
+		v, ok := ct.Get(k)
+		if !ok || v != wantV {
+			t.Fatalf("Get(%q) = %q, %v, want %q, true", k, v, ok, wantV)
+		}
+	}
+	if ct.Len() != len(want) {
+		t.Fatalf("Len() = %d, want %d", ct.Len(), len(want))
+	}
+
+	old, deleted := ct.Delete("foo")
+	if !deleted || old != "foo2" {
+		t.Fatalf("Delete(foo) = %q, %v, want foo2, true", old, deleted)
+	}
+	if _, ok := ct.Get("foo"); ok {
+		t.Fatalf("foo should be gone after delete")
+	}
+	if v, ok := ct.Get("foobar"); !ok || v != "foobar" {
+		t.Fatalf("deleting foo should not disturb foobar, got %q, %v", v, ok)
+	}
+}
+
+func TestConcurrentTreeWalk(t *testing.T) {
+	ct := NewConcurrent[int]()
+	keys := []string{"a", "ab", "abc", "b"}
+	for _, k := range keys {
+		ct.Insert(k, len(k))
+	}
+
+	seen := map[string]int{}
+	ct.Walk(func(k string, v int) bool {
+		seen[k] = v
+		return false
+	})
+	if len(seen) != len(keys) {
+		t.Fatalf("Walk visited %d keys, want %d", len(seen), len(keys))
+	}
+}
+
+func TestConcurrentTreeSnapshotIsolation(t *testing.T) {
+	ct := NewConcurrent[string]()
+	ct.Insert("foo", "foo")
+
+	snap := ct.Snapshot()
+	ct.Insert("bar", "bar")
+	ct.Delete("foo")
+
+	if v, ok := snap.Get("foo"); !ok || v != "foo" {
+		t.Fatalf("snapshot should still see foo, got %q, %v", v, ok)
+	}
+	if _, ok := snap.Get("bar"); ok {
+		t.Fatalf("snapshot should not see bar inserted after it was taken")
+	}
+}
+
+func TestRangeSnapshot(t *testing.T) {
+	ct := NewConcurrent[string]()
+	// Keys deliberately share prefixes at different depths, and some
+	// are strict prefixes of others, so the seek has to descend
+	// through split nodes rather than just a flat list of edges.
+	keys := []string{"a", "ab", "abc", "abd", "ac", "b", "ba", "c"}
+	for _, k := range keys {
+		ct.Insert(k, k)
+	}
+
+	cases := []struct {
+		from, to string
+		want     []string
+	}{
+		{"ab", "ac", []string{"ab", "abc", "abd"}},
+		{"abc", "b", []string{"abc", "abd", "ac"}},
+		{"", "z", keys},
+		{"bb", "z", []string{"c"}},
+		{"z", "zz", nil},
+	}
+
+	for _, c := range cases {
+		var got []string
+		ct.RangeSnapshot(c.from, c.to, func(k string, v string) bool {
+			got = append(got, k)
+			return false
+		})
+		if len(got) != len(c.want) {
+			t.Fatalf("RangeSnapshot(%q, %q) = %v, want %v", c.from, c.to, got, c.want)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("RangeSnapshot(%q, %q) = %v, want %v", c.from, c.to, got, c.want)
+			}
+		}
+	}
+}
+
+func TestRangeSnapshotStopsEarly(t *testing.T) {
+	ct := NewConcurrent[string]()
+	for _, k := range []string{"a", "b", "c", "d"} {
+		ct.Insert(k, k)
+	}
+
+	var got []string
+	ct.RangeSnapshot("a", "z", func(k string, v string) bool {
+		got = append(got, k)
+		return k == "b"
+	})
+	want := []string{"a", "b"}
+	if len(got) != len(want) {
+		t.Fatalf("RangeSnapshot stopping early = %v, want %v", got, want)
+	}
+}
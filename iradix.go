@@ -0,0 +1,632 @@
+package radix
+
+import "strings"
+
+// ileafNode is used to represent a leaf value in the immutable tree.
+type ileafNode[T any] struct {
+	mutateCh chan struct{}
+	key      string
+	val      T
+}
+
+// iedge is used to represent an edge in the immutable tree.
+type iedge[T any] struct {
+	label byte
+	node  *Node[T]
+}
+
+// Node is an immutable node in the radix tree. Nodes are never
+// mutated in place; Insert and Delete return a new root whose
+// unchanged subtrees are shared with the original tree.
+type Node[T any] struct {
+	// mutateCh is closed when this node, or any node in the subtree
+	// rooted here, is replaced as part of a commit.
+	mutateCh chan struct{}
+
+	// leaf is the leaf value attached to this node, if any.
+	leaf *ileafNode[T]
+
+	// prefix is the common prefix we ignore.
+	prefix string
+
+	// edges are stored in-order for iteration.
+	edges iedges[T]
+}
+
+type iedges[T any] []iedge[T]
+
+func (e iedges[T]) Len() int           { return len(e) }
+func (e iedges[T]) Less(i, j int) bool { return e[i].label < e[j].label }
+func (e iedges[T]) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+
+func (n *Node[T]) isLeaf() bool {
+	return n.leaf != nil
+}
+
+// edge returns the edge index for a label and the child under it, or
+// nil as the child if there is no such edge.
+func (n *Node[T]) edge(label byte) (int, *Node[T]) {
+	num := len(n.edges)
+	idx := sortSearch(num, func(i int) bool {
+		return n.edges[i].label >= label
+	})
+	if idx < num && n.edges[idx].label == label {
+		return idx, n.edges[idx].node
+	}
+	return idx, nil
+}
+
+// Get is used to look up a specific key, returning the value and if
+// it was found.
+func (n *Node[T]) Get(k string) (value T, found bool) {
+	search := k
+	current := n
+	for {
+		if len(search) == 0 {
+			if current.isLeaf() {
+				return current.leaf.val, true
+			}
+			break
+		}
+		_, current = current.edge(search[0])
+		if current == nil {
+			break
+		}
+		if strings.HasPrefix(search, current.prefix) {
+			search = search[len(current.prefix):]
+		} else {
+			break
+		}
+	}
+	return
+}
+
+// LongestPrefix is like Get, but returns the longest prefix match
+// instead of an exact match.
+func (n *Node[T]) LongestPrefix(k string) (key string, value T, found bool) {
+	var last *ileafNode[T]
+	search := k
+	current := n
+	for {
+		if current.isLeaf() {
+			last = current.leaf
+		}
+		if len(search) == 0 {
+			break
+		}
+		_, current = current.edge(search[0])
+		if current == nil {
+			break
+		}
+		if strings.HasPrefix(search, current.prefix) {
+			search = search[len(current.prefix):]
+		} else {
+			break
+		}
+	}
+	if last != nil {
+		return last.key, last.val, true
+	}
+	return
+}
+
+// Walk walks the subtree rooted at n.
+func (n *Node[T]) Walk(fn WalkFn[T]) {
+	irecursiveWalk(n, fn)
+}
+
+// WalkPrefix walks the subtree under the given prefix.
+func (n *Node[T]) WalkPrefix(prefix string, fn WalkFn[T]) {
+	search := prefix
+	current := n
+	for {
+		if len(search) == 0 {
+			irecursiveWalk(current, fn)
+			return
+		}
+		_, current = current.edge(search[0])
+		if current == nil {
+			return
+		}
+		if strings.HasPrefix(search, current.prefix) {
+			search = search[len(current.prefix):]
+			continue
+		}
+		if strings.HasPrefix(current.prefix, search) {
+			irecursiveWalk(current, fn)
+		}
+		return
+	}
+}
+
+func irecursiveWalk[T any](n *Node[T], fn WalkFn[T]) bool {
+	if n.leaf != nil && fn(n.leaf.key, n.leaf.val) {
+		return true
+	}
+	for _, e := range n.edges {
+		if irecursiveWalk(e.node, fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortSearch mirrors sort.Search without importing it twice per file;
+// kept local so the immutable path has no dependency on node[T].
+func sortSearch(n int, f func(int) bool) int {
+	lo, hi := 0, n
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if !f(mid) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// ITree is an immutable, persistent radix tree. Insert and Delete
+// never mutate the receiver; they return a new *ITree[T] that shares
+// untouched subtrees with the original, so older snapshots remain
+// valid and safe to read concurrently with further writes.
+type ITree[T any] struct {
+	root *Node[T]
+	size int
+}
+
+// NewI returns an empty immutable tree.
+func NewI[T any]() *ITree[T] {
+	return &ITree[T]{root: &Node[T]{mutateCh: make(chan struct{})}}
+}
+
+// Len returns the number of elements in the tree.
+func (t *ITree[T]) Len() int {
+	return t.size
+}
+
+// Root returns the root node of the tree, which can be used for
+// read-only access without going through a Txn.
+func (t *ITree[T]) Root() *Node[T] {
+	return t.root
+}
+
+// Get is used to look up a specific key, returning the value and if
+// it was found.
+func (t *ITree[T]) Get(k string) (T, bool) {
+	return t.root.Get(k)
+}
+
+// GetWatch is used to look up a specific key, returning the value,
+// a channel that is closed when the value changes, and if it was found.
+func (t *ITree[T]) GetWatch(k string) (watch <-chan struct{}, value T, found bool) {
+	search := k
+	current := t.root
+	for {
+		watch = current.mutateCh
+		if len(search) == 0 {
+			if current.isLeaf() {
+				return watch, current.leaf.val, true
+			}
+			break
+		}
+		_, current = current.edge(search[0])
+		if current == nil {
+			break
+		}
+		if strings.HasPrefix(search, current.prefix) {
+			search = search[len(current.prefix):]
+		} else {
+			break
+		}
+	}
+	return
+}
+
+// LongestPrefix is like Get, but instead of an exact match, it returns
+// the longest prefix match.
+func (t *ITree[T]) LongestPrefix(k string) (string, T, bool) {
+	return t.root.LongestPrefix(k)
+}
+
+// LongestPrefixWatch is like LongestPrefix, but also returns a channel
+// that is closed when the matched node is modified.
+func (t *ITree[T]) LongestPrefixWatch(k string) (watch <-chan struct{}, key string, value T, found bool) {
+	var last *ileafNode[T]
+	var lastWatch <-chan struct{}
+	search := k
+	current := t.root
+	for {
+		if current.isLeaf() {
+			last = current.leaf
+			lastWatch = current.mutateCh
+		}
+		if len(search) == 0 {
+			break
+		}
+		_, current = current.edge(search[0])
+		if current == nil {
+			break
+		}
+		if strings.HasPrefix(search, current.prefix) {
+			search = search[len(current.prefix):]
+		} else {
+			break
+		}
+	}
+	if last != nil {
+		return lastWatch, last.key, last.val, true
+	}
+	return t.root.mutateCh, "", value, false
+}
+
+// Insert returns a new tree with the value inserted at k, along with
+// the value replaced (if any) and whether an existing entry was
+// updated. The receiver is left unmodified.
+func (t *ITree[T]) Insert(k string, v T) (newTree *ITree[T], existing T, updated bool) {
+	txn := t.Txn()
+	existing, updated = txn.Insert(k, v)
+	newTree, _ = txn.Commit()
+	return newTree, existing, updated
+}
+
+// Delete returns a new tree with the key removed, along with the
+// deleted value and whether it was present. The receiver is left
+// unmodified.
+func (t *ITree[T]) Delete(k string) (newTree *ITree[T], existing T, deleted bool) {
+	txn := t.Txn()
+	existing, deleted = txn.Delete(k)
+	newTree, _ = txn.Commit()
+	return newTree, existing, deleted
+}
+
+// Walk walks the tree in sorted order.
+func (t *ITree[T]) Walk(fn WalkFn[T]) {
+	t.root.Walk(fn)
+}
+
+// WalkPrefix walks the tree under a prefix.
+func (t *ITree[T]) WalkPrefix(prefix string, fn WalkFn[T]) {
+	t.root.WalkPrefix(prefix, fn)
+}
+
+// ToMap walks the tree and converts it into a map.
+func (t *ITree[T]) ToMap() map[string]T {
+	out := make(map[string]T, t.size)
+	t.Walk(func(k string, v T) bool {
+		out[k] = v
+		return false
+	})
+	return out
+}
+
+// ITxn is a transaction on an ITree. Mutations made through a Txn are
+// batched: nodes are cloned lazily, at most once per transaction, so
+// a series of inserts/deletes pays for copy-on-write only on the path
+// it actually touches. Call Commit to obtain the new root.
+type ITxn[T any] struct {
+	// root is the current root of the transaction, which may differ
+	// from the snapshot root as mutations are applied.
+	root *Node[T]
+	snap *Node[T]
+	size int
+
+	// writable tracks nodes that have already been cloned for this
+	// transaction, keyed by the new (writable) node pointer, so a
+	// transaction only clones each node on the path once.
+	writable map[*Node[T]]bool
+
+	// trackChannels accumulates the mutateCh of every node visited
+	// on a mutating path; on Commit these channels are closed so
+	// watchers wake up.
+	trackChannels map[chan struct{}]struct{}
+}
+
+// Txn starts a new transaction against the tree.
+func (t *ITree[T]) Txn() *ITxn[T] {
+	return &ITxn[T]{
+		root: t.root,
+		snap: t.root,
+		size: t.size,
+	}
+}
+
+func (txn *ITxn[T]) trackChannel(ch chan struct{}) {
+	if txn.trackChannels == nil {
+		txn.trackChannels = make(map[chan struct{}]struct{})
+	}
+	txn.trackChannels[ch] = struct{}{}
+}
+
+// writeNode returns a node that is safe to mutate in place for the
+// rest of this transaction, cloning n the first time it is visited.
+// The original mutateCh is recorded so it gets closed on Commit.
+func (txn *ITxn[T]) writeNode(n *Node[T]) *Node[T] {
+	if txn.writable == nil {
+		txn.writable = make(map[*Node[T]]bool)
+	}
+	if txn.writable[n] {
+		return n
+	}
+	txn.trackChannel(n.mutateCh)
+
+	nc := &Node[T]{
+		mutateCh: make(chan struct{}),
+		leaf:     n.leaf,
+		prefix:   n.prefix,
+	}
+	if len(n.edges) != 0 {
+		nc.edges = make(iedges[T], len(n.edges))
+		copy(nc.edges, n.edges)
+	}
+	txn.writable[nc] = true
+	return nc
+}
+
+// Insert is used to add or update a value, tracking watch channels
+// for every node cloned along the path.
+func (txn *ITxn[T]) Insert(k string, v T) (existing T, updated bool) { //nolint: funlen
+	newRoot, oldVal, didUpdate := txn.insert(txn.root, k, k, v)
+	if newRoot != nil {
+		txn.root = newRoot
+	}
+	if !didUpdate {
+		txn.size++
+	}
+	return oldVal, didUpdate
+}
+
+func (txn *ITxn[T]) insert(n *Node[T], k, search string, v T) (*Node[T], T, bool) { //nolint: funlen
+	if len(search) == 0 {
+		nc := txn.writeNode(n)
+		if nc.isLeaf() {
+			old := nc.leaf.val
+			txn.trackChannel(nc.leaf.mutateCh)
+			nc.leaf = &ileafNode[T]{mutateCh: make(chan struct{}), key: k, val: v}
+			return nc, old, true
+		}
+		nc.leaf = &ileafNode[T]{mutateCh: make(chan struct{}), key: k, val: v}
+		var zero T
+		return nc, zero, false
+	}
+
+	idx, child := n.edge(search[0])
+	if child == nil {
+		e := iedge[T]{
+			label: search[0],
+			node: &Node[T]{
+				mutateCh: make(chan struct{}),
+				leaf:     &ileafNode[T]{mutateCh: make(chan struct{}), key: k, val: v},
+				prefix:   search,
+			},
+		}
+		nc := txn.writeNode(n)
+		nc.edges = append(iedges[T]{}, nc.edges...)
+		nc.edges = append(nc.edges, e)
+		sortIEdges(nc.edges)
+		var zero T
+		return nc, zero, false
+	}
+
+	commonPrefix := longestPrefix(search, child.prefix)
+	if commonPrefix == len(child.prefix) {
+		newChild, oldVal, didUpdate := txn.insert(child, k, search[commonPrefix:], v)
+		nc := txn.writeNode(n)
+		nc.edges = append(iedges[T]{}, nc.edges...)
+		for i := range nc.edges {
+			if nc.edges[i].label == search[0] {
+				nc.edges[i].node = newChild
+				break
+			}
+		}
+		return nc, oldVal, didUpdate
+	}
+
+	// Split the node. child itself is being replaced by modChild below,
+	// so its watchers need to be woken on commit just like any other
+	// node writeNode would have cloned.
+	txn.trackChannel(child.mutateCh)
+	splitNode := &Node[T]{
+		mutateCh: make(chan struct{}),
+		prefix:   search[:commonPrefix],
+	}
+	modChild := &Node[T]{
+		mutateCh: make(chan struct{}),
+		leaf:     child.leaf,
+		prefix:   child.prefix[commonPrefix:],
+		edges:    child.edges,
+	}
+	splitNode.edges = append(splitNode.edges, iedge[T]{
+		label: modChild.prefix[0],
+		node:  modChild,
+	})
+
+	search = search[commonPrefix:]
+	leaf := &ileafNode[T]{mutateCh: make(chan struct{}), key: k, val: v}
+	if len(search) == 0 {
+		splitNode.leaf = leaf
+	} else {
+		splitNode.edges = append(splitNode.edges, iedge[T]{
+			label: search[0],
+			node: &Node[T]{
+				mutateCh: make(chan struct{}),
+				leaf:     leaf,
+				prefix:   search,
+			},
+		})
+	}
+	sortIEdges(splitNode.edges)
+
+	nc := txn.writeNode(n)
+	nc.edges = append(iedges[T]{}, nc.edges...)
+	nc.edges[idx].node = splitNode
+	var zero T
+	return nc, zero, false
+}
+
+// Delete removes a key from the transaction's working tree.
+func (txn *ITxn[T]) Delete(k string) (existing T, deleted bool) {
+	newRoot, leaf := txn.delete(txn.root, k)
+	if newRoot != nil {
+		txn.root = newRoot
+	}
+	if leaf != nil {
+		txn.size--
+		return leaf.val, true
+	}
+	var zero T
+	return zero, false
+}
+
+func (txn *ITxn[T]) delete(n *Node[T], search string) (*Node[T], *ileafNode[T]) { //nolint: cyclop
+	if len(search) == 0 {
+		if !n.isLeaf() {
+			return nil, nil
+		}
+		nc := txn.writeNode(n)
+		oldLeaf := nc.leaf
+		txn.trackChannel(oldLeaf.mutateCh)
+		nc.leaf = nil
+		if n != txn.root && len(nc.edges) == 1 {
+			txn.trackChannel(nc.edges[0].node.mutateCh)
+			nc.mergeChild()
+		}
+		return nc, oldLeaf
+	}
+
+	label := search[0]
+	_, child := n.edge(label)
+	if child == nil || !strings.HasPrefix(search, child.prefix) {
+		return nil, nil
+	}
+
+	newChild, leaf := txn.delete(child, search[len(child.prefix):])
+	if newChild == nil {
+		return nil, nil
+	}
+
+	nc := txn.writeNode(n)
+	if newChild.leaf == nil && len(newChild.edges) == 0 {
+		nc.edges = append(iedges[T]{}, nc.edges...)
+		nc.delIEdge(label)
+		if n != txn.root && len(nc.edges) == 1 && !nc.isLeaf() {
+			txn.trackChannel(nc.edges[0].node.mutateCh)
+			nc.mergeChild()
+		}
+		return nc, leaf
+	}
+
+	nc.edges = append(iedges[T]{}, nc.edges...)
+	for i := range nc.edges {
+		if nc.edges[i].label == label {
+			nc.edges[i].node = newChild
+			break
+		}
+	}
+	return nc, leaf
+}
+
+func (n *Node[T]) mergeChild() {
+	e := n.edges[0]
+	child := e.node
+	n.prefix += child.prefix
+	n.leaf = child.leaf
+	n.edges = child.edges
+}
+
+func (n *Node[T]) delIEdge(label byte) {
+	num := len(n.edges)
+	idx := sortSearch(num, func(i int) bool {
+		return n.edges[i].label >= label
+	})
+	if idx < num && n.edges[idx].label == label {
+		copy(n.edges[idx:], n.edges[idx+1:])
+		n.edges[len(n.edges)-1] = iedge[T]{}
+		n.edges = n.edges[:len(n.edges)-1]
+	}
+}
+
+func sortIEdges[T any](e iedges[T]) {
+	for i := 1; i < len(e); i++ {
+		for j := i; j > 0 && e[j-1].label > e[j].label; j-- {
+			e[j-1], e[j] = e[j], e[j-1]
+		}
+	}
+}
+
+// Commit finalizes the transaction, returning the new immutable root
+// as an *ITree[T] plus every mutateCh closed as a result - the set of
+// channels returned by a prior Watch/GetWatch/LongestPrefixWatch call
+// that callers should now treat as fired.
+func (txn *ITxn[T]) Commit() (newTree *ITree[T], closed []chan struct{}) {
+	newTree = txn.CommitOnly()
+	closed = txn.Notify()
+	return newTree, closed
+}
+
+// CommitOnly finalizes the transaction and returns the new tree
+// without closing any watch channels; call Notify separately (or not
+// at all, if watchers should only be woken once all related txns are
+// committed).
+func (txn *ITxn[T]) CommitOnly() *ITree[T] {
+	return &ITree[T]{root: txn.root, size: txn.size}
+}
+
+// Notify closes every watch channel collected by this transaction and
+// returns them, so the caller can tell which Watch/GetWatch channels
+// just fired. Safe to call once; a nil receiver channel set is a
+// no-op.
+func (txn *ITxn[T]) Notify() []chan struct{} {
+	closed := make([]chan struct{}, 0, len(txn.trackChannels))
+	for ch := range txn.trackChannels {
+		close(ch)
+		closed = append(closed, ch)
+	}
+	txn.trackChannels = nil
+	return closed
+}
+
+// Watch returns a channel that is closed when prefix changes; Watch
+// itself does not block. With notifySubtree false, and
+// prefix an existing key, the returned channel is scoped to that exact
+// key: it closes when that key's value is updated or deleted, but not
+// when an unrelated descendant under prefix changes. With notifySubtree
+// true, or when prefix is not itself an existing key, the channel
+// covers the whole subtree rooted at (or nearest to) prefix, closing on
+// any change at or below it. If a commit has already replaced the
+// watched node since t was produced, the returned channel is already
+// closed.
+func (t *ITree[T]) Watch(prefix string, notifySubtree bool) <-chan struct{} {
+	current := t.root
+	search := prefix
+	for {
+		if len(search) == 0 {
+			if !notifySubtree && current.isLeaf() {
+				// current represents exactly prefix and holds a leaf of
+				// its own: the leaf's own channel is closed only when
+				// that leaf's value changes (see insert/delete), so it
+				// is strictly narrower than current.mutateCh, which also
+				// fires on changes to descendants under prefix.
+				return current.leaf.mutateCh
+			}
+			return current.mutateCh
+		}
+		_, child := current.edge(search[0])
+		if child == nil {
+			return current.mutateCh
+		}
+		if strings.HasPrefix(search, child.prefix) {
+			current = child
+			search = search[len(child.prefix):]
+			continue
+		}
+		if strings.HasPrefix(child.prefix, search) {
+			// search ends partway along child's compressed edge, short
+			// of any node boundary - there is no leaf exactly at prefix,
+			// so even an exact watch has nothing narrower than child's
+			// own subtree channel to fall back on.
+			return child.mutateCh
+		}
+		return current.mutateCh
+	}
+}
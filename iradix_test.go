@@ -0,0 +1,189 @@
+package radix
+
+import (
+	"testing"
+	"time"
+)
+
+func TestITreeInsertGetDelete(t *testing.T) {
+	tree := NewI[string]()
+
+	tree, _, updated := tree.Insert("foo", "foo")
+	if updated {
+		t.Fatalf("expected a fresh insert")
+	}
+	tree, old, updated := tree.Insert("foo", "foo2")
+	if !updated || old != "foo" {
+		t.Fatalf("expected update of existing key, got old=%q updated=%v", old, updated)
+	}
+
+	// Insert keys for which an existing key is a strict prefix
+	// ("foo" -> "foobar" -> "foobarbaz", "g" -> "go").
+	tree, _, _ = tree.Insert("foobar", "foobar")
+	tree, _, _ = tree.Insert("foobarbaz", "foobarbaz")
+	tree, _, _ = tree.Insert("g", "g")
+	tree, _, _ = tree.Insert("go", "go")
+
+	want := map[string]string{
+		"foo":       "foo2",
+		"foobar":    "foobar",
+		"foobarbaz": "foobarbaz",
+		"g":         "g",
+		"go":        "go",
+	}
+	for k, wantV := range want {
+		v, ok := tree.Get(k)
+		if !ok || v != wantV {
+			t.Fatalf("Get(%q) = %q, %v, want %q, true", k, v, ok, wantV)
+		}
+	}
+	if _, ok := tree.Get("missing"); ok {
+		t.Fatalf("expected missing key to be absent")
+	}
+
+	tree, old, deleted := tree.Delete("foo")
+	if !deleted || old != "foo2" {
+		t.Fatalf("Delete(foo) = %q, %v, want foo2, true", old, deleted)
+	}
+	if _, ok := tree.Get("foo"); ok {
+		t.Fatalf("foo should be gone after delete")
+	}
+	if v, ok := tree.Get("foobar"); !ok || v != "foobar" {
+		t.Fatalf("deleting foo should not disturb foobar, got %q, %v", v, ok)
+	}
+}
+
+func TestITreeWalk(t *testing.T) {
+	tree := NewI[int]()
+	keys := []string{"a", "ab", "abc", "b"}
+	for _, k := range keys {
+		tree, _, _ = tree.Insert(k, len(k))
+	}
+
+	seen := map[string]int{}
+	tree.Walk(func(k string, v int) bool {
+		seen[k] = v
+		return false
+	})
+	if len(seen) != len(keys) {
+		t.Fatalf("Walk visited %d keys, want %d", len(seen), len(keys))
+	}
+	for _, k := range keys {
+		if seen[k] != len(k) {
+			t.Fatalf("Walk value for %q = %d, want %d", k, seen[k], len(k))
+		}
+	}
+}
+
+func assertFires(t *testing.T, ch <-chan struct{}, wantFire bool) {
+	t.Helper()
+	select {
+	case <-ch:
+		if !wantFire {
+			t.Fatalf("channel fired, expected it not to")
+		}
+	case <-time.After(10 * time.Millisecond):
+		if wantFire {
+			t.Fatalf("channel did not fire, expected it to")
+		}
+	}
+}
+
+func TestITreeWatchExactVsSubtree(t *testing.T) {
+	tree := NewI[string]()
+	tree, _, _ = tree.Insert("foo", "foo")
+	tree, _, _ = tree.Insert("foobar", "foobar")
+
+	// "foo" is a node boundary with both a leaf of its own and a
+	// "foobar" descendant below it. An exact watch on "foo" must be
+	// scoped to foo's own value and ignore a change to the unrelated
+	// descendant "foobar"; a subtree watch must fire on either.
+	exactWatch := tree.Watch("foo", false)
+	subtreeWatch := tree.Watch("foo", true)
+
+	txn := tree.Txn()
+	txn.Insert("foobar", "changed")
+	tree, _ = txn.Commit()
+	assertFires(t, exactWatch, false)
+	assertFires(t, subtreeWatch, true)
+
+	// Now change "foo" itself: the exact watch obtained above (still
+	// open, since the previous write didn't touch it) must fire.
+	txn = tree.Txn()
+	txn.Insert("foo", "changed")
+	_, _ = txn.Commit()
+	assertFires(t, exactWatch, true)
+}
+
+func TestITreeWatchPartialEdgeIgnoresDivergence(t *testing.T) {
+	tree := NewI[string]()
+	tree, _, _ = tree.Insert("foobar", "foobar")
+
+	// "fooba" falls inside the compressed edge leading to the "foobar"
+	// node - there is no node boundary exactly at "fooba", so even an
+	// exact watch there has nothing narrower than "foobar"'s own
+	// subtree channel to fall back on. Both modes should ignore an
+	// unrelated sibling write and fire once "foobar" itself changes.
+	exactWatch := tree.Watch("fooba", false)
+	subtreeWatch := tree.Watch("fooba", true)
+
+	txn := tree.Txn()
+	txn.Insert("qux", "qux")
+	unrelated, _ := txn.Commit()
+	assertFires(t, exactWatch, false)
+	assertFires(t, subtreeWatch, false)
+
+	txn = unrelated.Txn()
+	txn.Insert("foobar", "changed")
+	_, _ = txn.Commit()
+	assertFires(t, exactWatch, true)
+	assertFires(t, subtreeWatch, true)
+}
+
+func TestITreeWatchSplitWakesSubtree(t *testing.T) {
+	tree := NewI[string]()
+	tree, _, _ = tree.Insert("foobar", "foobar")
+
+	// Inserting "foobaz" shares the "fooba" prefix with "foobar" and
+	// forces a split of the node holding "foobar", replacing it with a
+	// new Node object even though foobar's own value is untouched. A
+	// subtree watch must still wake, since the split path tracks and
+	// closes the replaced node's channel; an exact watch must not, since
+	// nothing about foobar's value changed.
+	exactWatch := tree.Watch("foobar", false)
+	subtreeWatch := tree.Watch("foobar", true)
+
+	txn := tree.Txn()
+	txn.Insert("foobaz", "foobaz")
+	_, _ = txn.Commit()
+
+	assertFires(t, exactWatch, false)
+	assertFires(t, subtreeWatch, true)
+}
+
+func TestITxnCommitReturnsClosedChannels(t *testing.T) {
+	tree := NewI[string]()
+	tree, _, _ = tree.Insert("foo", "foo")
+
+	watch, _, ok := tree.GetWatch("foo")
+	if !ok {
+		t.Fatalf("GetWatch(foo): key not found")
+	}
+
+	txn := tree.Txn()
+	txn.Insert("foo", "bar")
+	_, closed := txn.Commit()
+
+	if len(closed) == 0 {
+		t.Fatalf("expected Commit to report at least one closed channel")
+	}
+	found := false
+	for _, ch := range closed {
+		if ch == watch {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Commit did not report the channel watching the mutated key")
+	}
+}
@@ -0,0 +1,248 @@
+package radix
+
+import (
+	"sort"
+	"strings"
+)
+
+// iterPair holds a single key/value the iterator has already visited,
+// so Prev can replay it without re-walking the tree.
+type iterPair[T any] struct {
+	key string
+	val T
+}
+
+// iterFrame is one level of the explicit descent stack used by
+// Iterator. Unlike recursiveWalk, which can only unwind via the
+// WalkFn callback, a frame records exactly how far traversal has
+// gotten into a node (its own leaf, then each edge in order), so
+// Next can pause after a single key and resume later.
+type iterFrame[T any] struct {
+	node     *node[T]
+	leafDone bool
+	edgeIdx  int
+}
+
+// Iterator walks a Tree[T] in ascending key order, one key at a time,
+// without requiring a WalkFn callback. It supports SeekPrefix and
+// SeekLowerBound to jump to a starting point, and Prev to step back
+// over keys already produced by Next.
+//
+// Supporting Prev costs memory: every key Next produces is appended to
+// buf and kept for the lifetime of the Iterator, so a full forward
+// scan holds as many entries as the tree has keys, on top of the
+// explicit descent stack. An Iterator that never calls Prev, or a
+// caller that only needs a one-shot forward scan, is better served by
+// Walk/WalkPrefix, which stream through WalkFn without materializing
+// anything.
+type Iterator[T any] struct {
+	root  *node[T]
+	stack []iterFrame[T]
+	done  bool
+
+	buf []iterPair[T]
+	pos int // index into buf of the last key returned by Next/Prev; -1 before the first call
+}
+
+// Iterator returns an Iterator positioned before the first key.
+func (t *Tree[T]) Iterator() *Iterator[T] {
+	it := &Iterator[T]{root: t.root}
+	it.reset(t.root)
+	return it
+}
+
+func (it *Iterator[T]) reset(n *node[T]) {
+	it.stack = nil
+	it.buf = nil
+	it.pos = -1
+	it.done = n == nil
+	if n != nil {
+		it.stack = append(it.stack, iterFrame[T]{node: n})
+	}
+}
+
+// SeekPrefix repositions the iterator so that Next returns the keys
+// under prefix, in ascending order, and nothing else.
+func (it *Iterator[T]) SeekPrefix(prefix string) {
+	current := it.root
+	search := prefix
+	for {
+		if len(search) == 0 {
+			it.reset(current)
+			return
+		}
+		current = current.getEdge(search[0])
+		if current == nil {
+			it.reset(nil)
+			return
+		}
+		if strings.HasPrefix(search, current.prefix) {
+			search = search[len(current.prefix):]
+			continue
+		}
+		if strings.HasPrefix(current.prefix, search) {
+			it.reset(current)
+			return
+		}
+		it.reset(nil)
+		return
+	}
+}
+
+// SeekLowerBound repositions the iterator so that the next call to
+// Next returns the first key >= k in lexicographic order.
+func (it *Iterator[T]) SeekLowerBound(k string) { //nolint: cyclop
+	it.stack = nil
+	it.buf = nil
+	it.pos = -1
+	it.done = false
+
+	n := it.root
+	search := k
+	for {
+		if n == nil {
+			it.done = true
+			return
+		}
+		if len(search) == 0 {
+			// Every key in this subtree is >= search.
+			it.stack = append(it.stack, iterFrame[T]{node: n})
+			return
+		}
+
+		idx := sort.Search(len(n.edges), func(i int) bool {
+			return n.edges[i].label >= search[0]
+		})
+		// n's own leaf (if any) represents a strictly shorter key
+		// than search sharing everything matched so far, so it is
+		// always < search here; skip it.
+		it.stack = append(it.stack, iterFrame[T]{node: n, leafDone: true, edgeIdx: idx})
+
+		if idx == len(n.edges) {
+			return
+		}
+		child := n.edges[idx].node
+
+		switch {
+		case child.prefix[0] > search[0]:
+			return
+		case strings.HasPrefix(search, child.prefix):
+			// We are about to descend into child ourselves, so the
+			// frame just pushed for n must resume just past it -
+			// otherwise advance() would redescend into child later.
+			it.stack[len(it.stack)-1].edgeIdx = idx + 1
+			search = search[len(child.prefix):]
+			n = child
+			continue
+		case strings.HasPrefix(child.prefix, search):
+			return
+		default:
+			common := longestPrefix(search, child.prefix)
+			if child.prefix[common] > search[common] {
+				return
+			}
+			// child's subtree is entirely < search; skip past it.
+			it.stack[len(it.stack)-1].edgeIdx = idx + 1
+			return
+		}
+	}
+}
+
+// advance advances the underlying forward descent by exactly one key,
+// appending it to buf. Returns false once the subtree is exhausted.
+func (it *Iterator[T]) advance() bool {
+	for len(it.stack) > 0 {
+		top := &it.stack[len(it.stack)-1]
+		if !top.leafDone {
+			top.leafDone = true
+			if top.node.isLeaf() {
+				it.buf = append(it.buf, iterPair[T]{key: top.node.leaf.key, val: top.node.leaf.val})
+				return true
+			}
+		}
+		if top.edgeIdx < len(top.node.edges) {
+			child := top.node.edges[top.edgeIdx].node
+			top.edgeIdx++
+			it.stack = append(it.stack, iterFrame[T]{node: child})
+			continue
+		}
+		it.stack = it.stack[:len(it.stack)-1]
+	}
+	return false
+}
+
+// Next returns the next key/value in ascending order, or found=false
+// once the iterator is exhausted.
+func (it *Iterator[T]) Next() (key string, value T, found bool) {
+	if it.pos+1 < len(it.buf) {
+		it.pos++
+		p := it.buf[it.pos]
+		return p.key, p.val, true
+	}
+	if !it.advance() {
+		return
+	}
+	it.pos = len(it.buf) - 1
+	p := it.buf[it.pos]
+	return p.key, p.val, true
+}
+
+// Prev returns the key/value immediately before the last one
+// returned by Next, or found=false if already at the start.
+func (it *Iterator[T]) Prev() (key string, value T, found bool) {
+	if it.pos <= 0 {
+		return
+	}
+	it.pos--
+	p := it.buf[it.pos]
+	return p.key, p.val, true
+}
+
+// reverseFrame is one level of ReverseIterator's descent stack. Edges
+// are consumed back-to-front, and a node's own leaf (which sorts
+// before all of its children) is visited only after its children.
+type reverseFrame[T any] struct {
+	node       *node[T]
+	edgeIdx    int // next edge to descend into, counting down from len(edges)
+	leafQueued bool
+}
+
+// ReverseIterator walks a Tree[T] in descending key order, one key at
+// a time. It is the counterpart to Iterator for callers who need
+// descending order without materializing every key first (the only
+// other option being ToMap followed by a manual sort).
+type ReverseIterator[T any] struct {
+	stack []reverseFrame[T]
+}
+
+// ReverseIterator returns a ReverseIterator positioned before the
+// last key.
+func (t *Tree[T]) ReverseIterator() *ReverseIterator[T] {
+	rit := &ReverseIterator[T]{}
+	if t.root != nil {
+		rit.stack = append(rit.stack, reverseFrame[T]{node: t.root, edgeIdx: len(t.root.edges)})
+	}
+	return rit
+}
+
+// Next returns the next key/value in descending order, or found=false
+// once the iterator is exhausted.
+func (rit *ReverseIterator[T]) Next() (key string, value T, found bool) {
+	for len(rit.stack) > 0 {
+		top := &rit.stack[len(rit.stack)-1]
+		if top.edgeIdx > 0 {
+			top.edgeIdx--
+			child := top.node.edges[top.edgeIdx].node
+			rit.stack = append(rit.stack, reverseFrame[T]{node: child, edgeIdx: len(child.edges)})
+			continue
+		}
+		if !top.leafQueued {
+			top.leafQueued = true
+			if top.node.isLeaf() {
+				return top.node.leaf.key, top.node.leaf.val, true
+			}
+		}
+		rit.stack = rit.stack[:len(rit.stack)-1]
+	}
+	return
+}
@@ -0,0 +1,116 @@
+package radix
+
+import "testing"
+
+func buildIteratorTestTree() *Tree[string] {
+	r := New[string]()
+	// "foo" is a strict prefix of "foobar", which is itself a strict
+	// prefix of "foobarbaz" - exercises seeking/iterating across
+	// split nodes and key-is-prefix-of-key boundaries.
+	for _, k := range []string{"foo", "foobar", "foobarbaz", "g", "go", "a"} {
+		r.Insert(k, k)
+	}
+	return r
+}
+
+func drain(it *Iterator[string]) []string {
+	var got []string
+	for {
+		k, _, ok := it.Next()
+		if !ok {
+			break
+		}
+		got = append(got, k)
+	}
+	return got
+}
+
+func TestIteratorNextAscending(t *testing.T) {
+	r := buildIteratorTestTree()
+	got := drain(r.Iterator())
+	want := []string{"a", "foo", "foobar", "foobarbaz", "g", "go"}
+	if len(got) != len(want) {
+		t.Fatalf("Next() produced %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Next() produced %v, want %v", got, want)
+		}
+	}
+}
+
+func TestIteratorSeekPrefix(t *testing.T) {
+	r := buildIteratorTestTree()
+	it := r.Iterator()
+	it.SeekPrefix("foo")
+	got := drain(it)
+	want := []string{"foo", "foobar", "foobarbaz"}
+	if len(got) != len(want) {
+		t.Fatalf("SeekPrefix(foo) then Next() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("SeekPrefix(foo) then Next() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestIteratorSeekLowerBound(t *testing.T) {
+	r := buildIteratorTestTree()
+	it := r.Iterator()
+	it.SeekLowerBound("foobar")
+	got := drain(it)
+	want := []string{"foobar", "foobarbaz", "g", "go"}
+	if len(got) != len(want) {
+		t.Fatalf("SeekLowerBound(foobar) then Next() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("SeekLowerBound(foobar) then Next() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestIteratorPrev(t *testing.T) {
+	r := buildIteratorTestTree()
+	it := r.Iterator()
+
+	k1, _, _ := it.Next() // "a"
+	k2, _, _ := it.Next() // "foo"
+	if k1 != "a" || k2 != "foo" {
+		t.Fatalf("Next(), Next() = %q, %q, want a, foo", k1, k2)
+	}
+
+	// Prev steps back to the state before the last Next call, i.e. to
+	// the key immediately before k2.
+	prevKey, _, ok := it.Prev()
+	if !ok || prevKey != k1 {
+		t.Fatalf("Prev() = %q, %v, want %q, true", prevKey, ok, k1)
+	}
+	if _, _, ok := it.Prev(); ok {
+		t.Fatalf("Prev() should report no more keys before the first")
+	}
+}
+
+func TestReverseIteratorDescending(t *testing.T) {
+	r := buildIteratorTestTree()
+	rit := r.ReverseIterator()
+
+	var got []string
+	for {
+		k, _, ok := rit.Next()
+		if !ok {
+			break
+		}
+		got = append(got, k)
+	}
+	want := []string{"go", "g", "foobarbaz", "foobar", "foo", "a"}
+	if len(got) != len(want) {
+		t.Fatalf("ReverseIterator = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ReverseIterator = %v, want %v", got, want)
+		}
+	}
+}
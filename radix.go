@@ -110,6 +110,12 @@ func (e edges[T]) Sort() {
 type Tree[T any] struct {
 	root *node[T]
 	size int
+
+	// encode and decode back MarshalBinary/UnmarshalBinary; they are
+	// resolved lazily (see resolveEncoder/resolveDecoder) unless set via
+	// SetCodec.
+	encode ValueEncoder[T]
+	decode ValueDecoder[T]
 }
 
 // New returns an empty Tree.
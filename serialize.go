@@ -0,0 +1,351 @@
+package radix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// binaryMagic identifies the on-disk format produced by WriteTo, so
+// ReadFrom can fail fast on unrelated data instead of misinterpreting
+// it as a (corrupt) tree.
+var binaryMagic = [4]byte{'R', 'd', 'x', '1'}
+
+// maxDecodedFieldLen bounds the prefix/key/value length fields decodeNode
+// reads off the stream before allocating a buffer for them. Without this,
+// a single corrupted or malicious length varint passed straight into
+// make([]byte, n) can request an allocation large enough to kill the
+// process with an unrecoverable out-of-memory error rather than a
+// catchable panic - a real risk since ReadFrom/UnmarshalBinary are meant
+// to accept arbitrary persisted or transmitted data.
+const maxDecodedFieldLen = 1 << 28 // 256 MiB
+
+// ValueEncoder encodes a single value to bytes for binary
+// serialization. See SetCodec.
+type ValueEncoder[T any] func(T) ([]byte, error)
+
+// ValueDecoder decodes bytes produced by a ValueEncoder back into a
+// value. See SetCodec.
+type ValueDecoder[T any] func([]byte) (T, error)
+
+// SetCodec overrides the value codec used by MarshalBinary,
+// UnmarshalBinary, WriteTo and ReadFrom. Without a call to SetCodec,
+// the tree picks a codec automatically: raw bytes for T = string or
+// []byte, and encoding/gob for everything else.
+func (t *Tree[T]) SetCodec(enc ValueEncoder[T], dec ValueDecoder[T]) {
+	t.encode, t.decode = enc, dec
+}
+
+func (t *Tree[T]) resolveEncoder() ValueEncoder[T] {
+	if t.encode != nil {
+		return t.encode
+	}
+	var zero T
+	switch any(zero).(type) {
+	case string:
+		return func(v T) ([]byte, error) { return []byte(any(v).(string)), nil }
+	case []byte:
+		return func(v T) ([]byte, error) { return any(v).([]byte), nil }
+	default:
+		return gobEncode[T]
+	}
+}
+
+func (t *Tree[T]) resolveDecoder() ValueDecoder[T] {
+	if t.decode != nil {
+		return t.decode
+	}
+	var zero T
+	switch any(zero).(type) {
+	case string:
+		return func(b []byte) (T, error) { return any(string(b)).(T), nil }
+	case []byte:
+		return func(b []byte) (T, error) { return any(append([]byte{}, b...)).(T), nil }
+	default:
+		return gobDecode[T]
+	}
+}
+
+func gobEncode[T any](v T) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gobDecode[T any](b []byte) (T, error) {
+	var v T
+	err := gob.NewDecoder(bytes.NewReader(b)).Decode(&v)
+	return v, err
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, serializing the
+// tree to the path-compressed format described on WriteTo.
+func (t *Tree[T]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := t.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing
+// the tree's contents with data produced by MarshalBinary/WriteTo.
+func (t *Tree[T]) UnmarshalBinary(data []byte) error {
+	_, err := t.ReadFrom(bytes.NewReader(data))
+	return err
+}
+
+// WriteTo serializes the tree in a compact, path-compressed format
+// suitable for persistence and warm-start: a 4-byte magic, a version
+// byte, a varint entry count, then a pre-order stream of node
+// records:
+//
+//	{prefix_len, prefix_bytes, has_leaf,
+//	 [leaf_key_len, leaf_key, value_len, value_bytes]?,
+//	 edge_count, [edge_label, child_record]*}
+//
+// Each child_record is itself a complete node record, so decoding
+// needs no separate framing around it: the varints embedded in the
+// record are enough to know exactly where it ends. Values are encoded
+// with the codec installed via SetCodec, or the automatic
+// string/[]byte/gob codec described there. Reconstructing a tree from
+// this format (see ReadFrom) walks the serialized structure directly
+// rather than re-running Insert key by key, so it avoids the
+// O(n*avg-key-len) cost and allocator churn of NewFromMap on a large,
+// previously-built tree.
+func (t *Tree[T]) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	if _, err := cw.Write(binaryMagic[:]); err != nil {
+		return cw.n, err
+	}
+	if _, err := cw.Write([]byte{1}); err != nil { // format version
+		return cw.n, err
+	}
+	if err := writeUvarint(cw, uint64(t.size)); err != nil {
+		return cw.n, err
+	}
+
+	enc := t.resolveEncoder()
+	err := encodeNode(cw, t.root, enc)
+	return cw.n, err
+}
+
+// ReadFrom replaces the tree's contents by deserializing data written
+// by WriteTo/MarshalBinary. It consumes exactly the bytes that make up
+// the encoded tree and does not check for (or consume) any trailing
+// data in r, a mild deviation from the usual io.ReaderFrom convention
+// of reading until EOF.
+func (t *Tree[T]) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countingReader{r: r}
+
+	var magic [4]byte
+	if _, err := io.ReadFull(cr, magic[:]); err != nil {
+		return cr.n, fmt.Errorf("radix: reading magic: %w", err)
+	}
+	if magic != binaryMagic {
+		return cr.n, errors.New("radix: not a radix-tree binary stream")
+	}
+	var version [1]byte
+	if _, err := io.ReadFull(cr, version[:]); err != nil {
+		return cr.n, err
+	}
+	if version[0] != 1 {
+		return cr.n, fmt.Errorf("radix: unsupported format version %d", version[0])
+	}
+
+	entryCount, err := readUvarint(cr)
+	if err != nil {
+		return cr.n, err
+	}
+
+	dec := t.resolveDecoder()
+	root, err := decodeNode(cr, dec)
+	if err != nil {
+		return cr.n, err
+	}
+
+	size := 0
+	recursiveWalk(root, func(string, T) bool {
+		size++
+		return false
+	})
+	if uint64(size) != entryCount {
+		return cr.n, fmt.Errorf("radix: entry count mismatch: header says %d, decoded %d", entryCount, size)
+	}
+
+	t.root = root
+	t.size = size
+	return cr.n, nil
+}
+
+// encodeNode serializes n and its subtree directly to w (see WriteTo
+// for the layout). It writes straight to the stream passed down from
+// WriteTo rather than building an intermediate buffer per node and
+// copying it into its parent's buffer, which would cost an extra copy
+// per ancestor for every node in a deep tree.
+func encodeNode[T any](w io.Writer, n *node[T], enc ValueEncoder[T]) error {
+	if err := writeUvarint(w, uint64(len(n.prefix))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, n.prefix); err != nil {
+		return err
+	}
+
+	if n.leaf != nil {
+		if _, err := w.Write([]byte{1}); err != nil {
+			return err
+		}
+		if err := writeUvarint(w, uint64(len(n.leaf.key))); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, n.leaf.key); err != nil {
+			return err
+		}
+
+		valBytes, err := enc(n.leaf.val)
+		if err != nil {
+			return fmt.Errorf("radix: encoding value for %q: %w", n.leaf.key, err)
+		}
+		if err := writeUvarint(w, uint64(len(valBytes))); err != nil {
+			return err
+		}
+		if _, err := w.Write(valBytes); err != nil {
+			return err
+		}
+	} else if _, err := w.Write([]byte{0}); err != nil {
+		return err
+	}
+
+	if err := writeUvarint(w, uint64(len(n.edges))); err != nil {
+		return err
+	}
+	for _, e := range n.edges {
+		if _, err := w.Write([]byte{e.label}); err != nil {
+			return err
+		}
+		if err := encodeNode(w, e.node, enc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeNode reads a single node record, and recursively its subtree.
+func decodeNode[T any](r *countingReader, dec ValueDecoder[T]) (*node[T], error) {
+	prefix, err := readBoundedBytes(r, "prefix")
+	if err != nil {
+		return nil, err
+	}
+	n := &node[T]{prefix: string(prefix)}
+
+	hasLeaf, err := readByte(r)
+	if err != nil {
+		return nil, err
+	}
+	if hasLeaf == 1 {
+		key, err := readBoundedBytes(r, "leaf key")
+		if err != nil {
+			return nil, err
+		}
+
+		valBytes, err := readBoundedBytes(r, "value")
+		if err != nil {
+			return nil, err
+		}
+		val, err := dec(valBytes)
+		if err != nil {
+			return nil, fmt.Errorf("radix: decoding value for %q: %w", key, err)
+		}
+		n.leaf = &leafNode[T]{key: string(key), val: val}
+	}
+
+	edgeCount, err := readUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < edgeCount; i++ {
+		label, err := readByte(r)
+		if err != nil {
+			return nil, err
+		}
+		child, err := decodeNode(r, dec)
+		if err != nil {
+			return nil, err
+		}
+		n.edges = append(n.edges, edge[T]{label: label, node: child})
+	}
+
+	return n, nil
+}
+
+// countingWriter tracks the total number of bytes written, so WriteTo
+// can satisfy the io.WriterTo contract without a second pass.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// countingReader tracks the total number of bytes read, so ReadFrom
+// can satisfy the io.ReaderFrom contract's return value.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+func (cr *countingReader) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(cr, b[:])
+	return b[0], err
+}
+
+func writeUvarint(w io.Writer, v uint64) error {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	_, err := w.Write(tmp[:n])
+	return err
+}
+
+func readUvarint(r io.ByteReader) (uint64, error) {
+	return binary.ReadUvarint(r)
+}
+
+// readBoundedBytes reads a length-prefixed byte field, rejecting a
+// length beyond maxDecodedFieldLen before allocating rather than
+// trusting it straight from the stream. what names the field in the
+// returned error, for context on which length was rejected.
+func readBoundedBytes(r *countingReader, what string) ([]byte, error) {
+	n, err := readUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxDecodedFieldLen {
+		return nil, fmt.Errorf("radix: %s length %d exceeds maximum of %d", what, n, maxDecodedFieldLen)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, fmt.Errorf("radix: reading %s: %w", what, err)
+	}
+	return b, nil
+}
+
+func readByte(r io.ByteReader) (byte, error) {
+	return r.ReadByte()
+}
@@ -0,0 +1,153 @@
+package radix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestTreeMarshalUnmarshalBinary(t *testing.T) {
+	r := New[string]()
+	// "foo" is a strict prefix of "foobar", and "foobarbaz" has
+	// "foobar" as a strict prefix - exercise the split-node layout on
+	// the way through the encode/decode round trip.
+	entries := map[string]string{
+		"foo":       "foo",
+		"foobar":    "foobar",
+		"foobarbaz": "foobarbaz",
+		"g":         "g",
+		"go":        "go",
+	}
+	for k, v := range entries {
+		r.Insert(k, v)
+	}
+
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	r2 := New[string]()
+	if err := r2.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+
+	if r2.Len() != len(entries) {
+		t.Fatalf("Len() = %d, want %d", r2.Len(), len(entries))
+	}
+	for k, wantV := range entries {
+		v, ok := r2.Get(k)
+		if !ok || v != wantV {
+			t.Fatalf("Get(%q) = %q, %v, want %q, true", k, v, ok, wantV)
+		}
+	}
+}
+
+func TestTreeWriteToReadFrom(t *testing.T) {
+	r := NewFromMap[int](map[string]int{
+		"a":  1,
+		"ab": 2,
+		"b":  3,
+	})
+
+	var buf bytes.Buffer
+	n, err := r.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Fatalf("WriteTo returned %d, but wrote %d bytes", n, buf.Len())
+	}
+
+	r2 := New[int]()
+	if _, err := r2.ReadFrom(&buf); err != nil {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+	if r2.Len() != r.Len() {
+		t.Fatalf("Len() = %d, want %d", r2.Len(), r.Len())
+	}
+	for _, k := range []string{"a", "ab", "b"} {
+		want, _ := r.Get(k)
+		got, ok := r2.Get(k)
+		if !ok || got != want {
+			t.Fatalf("Get(%q) = %d, %v, want %d, true", k, got, ok, want)
+		}
+	}
+}
+
+type gobValue struct {
+	Name  string
+	Count int
+}
+
+func TestTreeMarshalBinaryGobCodec(t *testing.T) {
+	r := New[gobValue]()
+	r.Insert("x", gobValue{Name: "x", Count: 1})
+	r.Insert("xy", gobValue{Name: "xy", Count: 2})
+
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	r2 := New[gobValue]()
+	if err := r2.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	v, ok := r2.Get("xy")
+	if !ok || v != (gobValue{Name: "xy", Count: 2}) {
+		t.Fatalf("Get(xy) = %+v, %v, want {xy 2}, true", v, ok)
+	}
+}
+
+func TestTreeSetCodec(t *testing.T) {
+	r := New[int]()
+	r.SetCodec(
+		func(v int) ([]byte, error) { return []byte{byte(v)}, nil },
+		func(b []byte) (int, error) { return int(b[0]), nil },
+	)
+	r.Insert("a", 42)
+
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	r2 := New[int]()
+	r2.SetCodec(
+		func(v int) ([]byte, error) { return []byte{byte(v)}, nil },
+		func(b []byte) (int, error) { return int(b[0]), nil },
+	)
+	if err := r2.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	if v, ok := r2.Get("a"); !ok || v != 42 {
+		t.Fatalf("Get(a) = %d, %v, want 42, true", v, ok)
+	}
+}
+
+func TestTreeUnmarshalBinaryRejectsBadMagic(t *testing.T) {
+	r := New[string]()
+	if err := r.UnmarshalBinary([]byte("not a radix stream")); err == nil {
+		t.Fatalf("expected an error for data with a bad magic")
+	}
+}
+
+func TestTreeUnmarshalBinaryRejectsHugeLengthField(t *testing.T) {
+	// A corrupted or malicious length field must be rejected with an
+	// error, not trusted straight into make([]byte, n): that would let
+	// a single flipped byte request an allocation large enough to kill
+	// the process with an out-of-memory error instead of a catchable
+	// one.
+	var buf bytes.Buffer
+	buf.Write(binaryMagic[:])
+	buf.WriteByte(1) // format version
+	var tmp [binary.MaxVarintLen64]byte
+	buf.Write(tmp[:binary.PutUvarint(tmp[:], 1)])     // entry count
+	buf.Write(tmp[:binary.PutUvarint(tmp[:], 1<<40)]) // node's prefix_len
+
+	r := New[string]()
+	if err := r.UnmarshalBinary(buf.Bytes()); err == nil {
+		t.Fatalf("expected an error for a huge length field, got nil")
+	}
+}